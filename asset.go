@@ -0,0 +1,171 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+)
+
+func (c *client) SubmitImageAsset(ctx context.Context, asset Asset, req PostImageRequest) (string, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.Resubmit = boolOr(req.Resubmit, c.defaultResubmit)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	name, err := c.submitImageAsset(ctx, asset, req)
+	if err != nil {
+		return "", postError(req.Subreddit, req.Title, err)
+	}
+	return name, nil
+}
+
+func (c *client) submitImageAsset(ctx context.Context, asset Asset, req PostImageRequest) (string, error) {
+	err := validateAsset("image", asset)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateAssetRequest(req.Title, req.Subreddit, req.Extra, req.FlairID, req.FlairName, req.FlairText)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	key := submitKey("image", req.Subreddit, req.Title, asset.Location)
+	name, ok, err := c.findRecentSubmit(ctx, key, req.Subreddit, req.Title)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return name, nil
+	}
+
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return "", err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return "", err
+	}
+
+	name, _, err = c.submitPost(ctx, key, asset.WebSocket, imageForm(req, asset.Location, nsfw))
+	if err != nil {
+		return "", fmt.Errorf("submitting post of asset %s: %w", asset.ID, err)
+	}
+	return name, nil
+}
+
+func (c *client) SubmitVideoAsset(ctx context.Context, video, thumbnail Asset, req PostVideoRequest) (string, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.Resubmit = boolOr(req.Resubmit, c.defaultResubmit)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	name, err := c.submitVideoAsset(ctx, video, thumbnail, req)
+	if err != nil {
+		return "", postError(req.Subreddit, req.Title, err)
+	}
+	return name, nil
+}
+
+func (c *client) submitVideoAsset(ctx context.Context, video, thumbnail Asset, req PostVideoRequest) (string, error) {
+	err := validateAsset("video", video)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateAsset("thumbnail", thumbnail)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateAssetRequest(req.Title, req.Subreddit, req.Extra, req.FlairID, req.FlairName, req.FlairText)
+	if err != nil {
+		return "", err
+	}
+
+	if req.PosterWidth < 0 || req.PosterHeight < 0 || (req.PosterWidth == 0) != (req.PosterHeight == 0) {
+		return "", fmt.Errorf("poster width and height must both be positive or both be 0")
+	}
+
+	err = validateMimeType(req.MimeType)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateFilename(req.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	if req.Kind == "" {
+		req.Kind = VideoKindVideo
+	}
+
+	if req.Kind != VideoKindVideo && req.Kind != VideoKindGif {
+		return "", fmt.Errorf("kind must be video or videogif")
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	key := submitKey(string(req.Kind), req.Subreddit, req.Title, video.Location, thumbnail.Location)
+	name, ok, err := c.findRecentSubmit(ctx, key, req.Subreddit, req.Title)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return name, nil
+	}
+
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return "", err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return "", err
+	}
+
+	form := videoForm(req, video.Location, thumbnail.Location, nsfw)
+	name, _, err = c.submitPost(ctx, key, video.WebSocket, form)
+	if err != nil {
+		return "", fmt.Errorf("submitting post of asset %s: %w", video.ID, err)
+	}
+	return name, nil
+}
+
+// validateAsset checks that asset, the media of a post of kind, was uploaded.
+func validateAsset(kind string, asset Asset) error {
+	if asset.Location == "" {
+		return fmt.Errorf("%s asset has no location; upload it with UploadMedia first", kind)
+	}
+	return nil
+}
+
+func validateAssetRequest(title, subreddit string, extra map[string]string, flairID, flairName, flairText string) error {
+	err := validateTitle(title)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubreddit(subreddit)
+	if err != nil {
+		return err
+	}
+
+	err = validateExtra(extra)
+	if err != nil {
+		return err
+	}
+
+	return validateFlair(flairID, flairName, flairText)
+}
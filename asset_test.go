@@ -0,0 +1,203 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSubmitAsset(t *testing.T) {
+	// reddit server. records the submitted form, nothing is uploaded
+	var form url.Values
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			form = r.PostForm
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithAsyncSubmit(true),
+	)
+
+	video := Asset{ID: "1", Location: "https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fvideo"}
+	thumbnail := Asset{ID: "2", Location: "https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fthumbnail"}
+
+	t.Run("Image", func(t *testing.T) {
+		// Given
+		form = nil
+		req := PostImageRequest{Subreddit: "subreddit", Title: "image test"}
+
+		// When
+		_, err := reddit.SubmitImageAsset(context.Background(), thumbnail, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if form.Get("kind") != "image" || form.Get("url") != thumbnail.Location {
+			t.Errorf("want an image post of %s, got %v", thumbnail.Location, form)
+		}
+	})
+
+	t.Run("Video", func(t *testing.T) {
+		// Given
+		form = nil
+		req := PostVideoRequest{Subreddit: "subreddit", Title: "video test"}
+
+		// When
+		_, err := reddit.SubmitVideoAsset(context.Background(), video, thumbnail, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if form.Get("kind") != "video" || form.Get("url") != video.Location || form.Get("video_poster_url") != thumbnail.Location {
+			t.Errorf("want a video post of %s with poster %s, got %v", video.Location, thumbnail.Location, form)
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		// Given
+		form = nil
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(httpClient),
+			WithAsyncSubmit(true),
+			WithDefaultNSFW(true),
+			WithDefaultResubmit(true),
+			WithDefaultSendReplies(true),
+		)
+
+		// the request's own value wins over the default
+		req := PostImageRequest{Subreddit: "subreddit", Title: "image test", SendReplies: Bool(false)}
+
+		// When
+		_, err := reddit.SubmitImageAsset(context.Background(), thumbnail, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if form.Get("nsfw") != "true" || form.Get("resubmit") != "true" || form.Get("sendreplies") != "false" {
+			t.Errorf("want nsfw and resubmit from the defaults and sendreplies false, got %v", form)
+		}
+	})
+
+	t.Run("NoLocation", func(t *testing.T) {
+		// Given
+		form = nil
+		req := PostVideoRequest{Subreddit: "subreddit", Title: "video test"}
+
+		// When
+		_, err := reddit.SubmitVideoAsset(context.Background(), video, Asset{ID: "2"}, req)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "asset has no location") {
+			t.Errorf("want a missing location error, got %v", err)
+		}
+
+		if form != nil {
+			t.Error("want nothing submitted")
+		}
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			submit  func() (string, error)
+			wantErr string
+		}{
+			{
+				"ImageExtra",
+				func() (string, error) {
+					req := PostImageRequest{Subreddit: "subreddit", Title: "image test", Extra: map[string]string{"kind": "link"}}
+					return reddit.SubmitImageAsset(context.Background(), thumbnail, req)
+				},
+				"extra field kind is set by redmed",
+			},
+			{
+				"VideoExtra",
+				func() (string, error) {
+					req := PostVideoRequest{Subreddit: "subreddit", Title: "video test", Extra: map[string]string{"url": "https://host.com"}}
+					return reddit.SubmitVideoAsset(context.Background(), video, thumbnail, req)
+				},
+				"extra field url is set by redmed",
+			},
+			{
+				"VideoPoster",
+				func() (string, error) {
+					req := PostVideoRequest{Subreddit: "subreddit", Title: "video test", PosterWidth: 640}
+					return reddit.SubmitVideoAsset(context.Background(), video, thumbnail, req)
+				},
+				"poster width and height must both be positive or both be 0",
+			},
+			{
+				"VideoFilename",
+				func() (string, error) {
+					req := PostVideoRequest{Subreddit: "subreddit", Title: "video test", Filename: "dir/video.mp4"}
+					return reddit.SubmitVideoAsset(context.Background(), video, thumbnail, req)
+				},
+				"filename dir/video.mp4 can't be a path",
+			},
+			{
+				"VideoMimeType",
+				func() (string, error) {
+					req := PostVideoRequest{Subreddit: "subreddit", Title: "video test", MimeType: "text/plain"}
+					return reddit.SubmitVideoAsset(context.Background(), video, thumbnail, req)
+				},
+				`mime type "text/plain" is not an image/* or video/* type`,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				// Given
+				form = nil
+
+				// When
+				_, err := tc.submit()
+
+				// Then
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("want error containing %q, got %v", tc.wantErr, err)
+				}
+
+				if form != nil {
+					t.Error("want nothing submitted")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package redmed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxConcurrentSubmits bounds how many subreddits PostToSubreddits submits to at
+// once, to stay clear of reddit's rate limit.
+const maxConcurrentSubmits = 4
+
+func (c *client) PostToSubreddits(ctx context.Context, req PostImageRequest, subreddits []string) ([]PostResult, error) {
+	if len(subreddits) == 0 {
+		return nil, fmt.Errorf("must provide subreddits")
+	}
+
+	if req.AsGallery {
+		return nil, fmt.Errorf("can't post a gallery to several subreddits")
+	}
+
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.Resubmit = boolOr(req.Resubmit, c.defaultResubmit)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+
+	results := make([]PostResult, len(subreddits))
+	postErrs := make([]error, len(subreddits))
+
+	// each subreddit gets the checks PostImage runs, into a new slice so the
+	// caller's isn't rewritten
+	reqs := make([]PostImageRequest, len(subreddits))
+	var ok bool
+	for i, subreddit := range subreddits {
+		reqs[i] = req
+		reqs[i].Subreddit = normalizeSubreddit(subreddit)
+
+		err := ValidateImageRequest(reqs[i])
+		if err != nil {
+			postErrs[i] = fmt.Errorf("r/%s: %w", reqs[i].Subreddit, err)
+			continue
+		}
+		ok = true
+	}
+
+	if !ok {
+		return results, errors.Join(postErrs...)
+	}
+
+	// PostImage posts a gif this large as a videogif, which would be uploaded
+	// again for each subreddit
+	largeGIF, err := c.isLargeGIF(ctx, req.Path, req.MimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	if largeGIF {
+		return nil, fmt.Errorf("gif is over %d bytes and can only be posted as a videogif; use PostVideo", maxImageSize)
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	// the subreddits are checked before uploading, so media isn't uploaded for
+	// nothing when none of them can take the post. a flair named by text has a
+	// different id in each
+	keys := make([]string, len(subreddits))
+	nsfw := make([]bool, len(subreddits))
+	ok = false
+	for i := range reqs {
+		if postErrs[i] != nil {
+			continue
+		}
+
+		subredditReq := &reqs[i]
+		keys[i] = submitKey("image", subredditReq.Subreddit, subredditReq.Title, subredditReq.Path)
+		name, found, err := c.findRecentSubmit(ctx, keys[i], subredditReq.Subreddit, subredditReq.Title)
+		if err == nil && found {
+			results[i] = PostResult{Name: name}
+			continue
+		}
+
+		if err == nil {
+			nsfw[i], err = c.checkSubreddit(ctx, subredditReq.Subreddit, boolValue(subredditReq.NSWF))
+		}
+
+		if err == nil {
+			subredditReq.FlairID, err = c.checkFlair(ctx, subredditReq.Subreddit, subredditReq.FlairID, subredditReq.FlairName)
+		}
+
+		if err != nil {
+			postErrs[i] = fmt.Errorf("r/%s: %w", subredditReq.Subreddit, err)
+			continue
+		}
+		ok = true
+	}
+
+	if !ok {
+		return results, errors.Join(postErrs...)
+	}
+
+	asset, err := c.reddit.uploadAsset(ctx, req.Path, req.Filename, req.MimeType, nil)
+	if err != nil {
+		return results, errors.Join(append(postErrs, fmt.Errorf("uploading asset: %w", err))...)
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(maxConcurrentSubmits)
+	for i := range reqs {
+		if postErrs[i] != nil || results[i].Name != "" {
+			continue
+		}
+
+		index := i
+		subredditReq := reqs[i]
+		eg.Go(func() error {
+			name, websocketURL, err := c.submitPost(ctx, keys[index], asset.WebSocket, imageForm(subredditReq, asset.Location, nsfw[index]))
+			if err != nil {
+				postErrs[index] = fmt.Errorf("r/%s: submitting post: %w", subredditReq.Subreddit, err)
+				return nil
+			}
+
+			results[index] = PostResult{Name: name, AssetID: asset.ID, DownloadPaths: downloadPaths(asset), WebsocketURL: websocketURL}
+			c.addPostMetadata(ctx, &results[index])
+			return nil
+		})
+	}
+	eg.Wait()
+
+	return results, errors.Join(postErrs...)
+}
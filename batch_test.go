@@ -0,0 +1,250 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPostToSubreddits(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. leases the asset and rejects posts to r/banned
+	var leases int32
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			atomic.AddInt32(&leases, 1)
+
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sr := r.PostForm.Get("sr")
+			if sr == "banned" {
+				w.Write([]byte(`{"json": {"errors": [["SUBREDDIT_NOTALLOWED", "you aren't allowed to post there.", "sr"]]}}`))
+				return
+			}
+			fmt.Fprintf(w, `{"json": {"errors": [], "data": {"name": "t3_%s"}}}`, sr)
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithDuplicateProtection(time.Minute),
+	)
+
+	req := PostImageRequest{
+		Path:  "testdata/testimg.jpeg",
+		Title: "image test",
+	}
+
+	// When
+	results, err := reddit.PostToSubreddits(context.Background(), req, []string{"r/one", "banned", "/r/two/"})
+
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "r/banned: submitting post") || strings.Contains(err.Error(), "r/one") {
+		t.Errorf("want only r/banned to fail, got %v", err)
+	}
+
+	want := []string{"t3_one", "", "t3_two"}
+	if len(results) != len(want) {
+		t.Fatalf("want %d results, got %d", len(want), len(results))
+	}
+
+	for i := range want {
+		if results[i].Name != want[i] {
+			t.Errorf("want result %d named %q, got %q", i, want[i], results[i].Name)
+		}
+	}
+
+	if leases != 1 {
+		t.Errorf("want the image uploaded once, got %d", leases)
+	}
+
+	// posting again returns the earlier posts without uploading the image again
+	results, err = reddit.PostToSubreddits(context.Background(), req, []string{"one", "two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || results[0].Name != "t3_one" || results[1].Name != "t3_two" {
+		t.Errorf("want the earlier posts, got %v", results)
+	}
+
+	if leases != 1 {
+		t.Errorf("want the image uploaded once, got %d", leases)
+	}
+}
+
+func TestPostToSubredditsInvalidRequest(t *testing.T) {
+	// a gif over the image limit, which PostImage would post as a videogif
+	largeGIF := filepath.Join(t.TempDir(), "large.gif")
+	f, err := os.Create(largeGIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.Truncate(maxImageSize + 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []struct {
+		name        string
+		req         PostImageRequest
+		wantErr     string
+		wantResults bool
+	}{
+		{
+			"Gallery",
+			PostImageRequest{Path: "testdata/testimg.jpeg", Title: "image test", AsGallery: true},
+			"can't post a gallery to several subreddits",
+			false,
+		},
+		{
+			"Video",
+			PostImageRequest{Path: "testdata/video.mp4", Title: "image test"},
+			"r/one: video/mp4 is not supported for image posts",
+			true,
+		},
+		{
+			"CaptionWithoutGallery",
+			PostImageRequest{Path: "testdata/testimg.jpeg", Title: "image test", Caption: "caption"},
+			"r/one: caption and outbound url need AsGallery",
+			true,
+		},
+		{
+			"LargeGIF",
+			PostImageRequest{Path: largeGIF, Title: "image test"},
+			"can only be posted as a videogif",
+			false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			// no test server: the request must be rejected before anything is sent
+			reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+			// When
+			results, err := reddit.PostToSubreddits(context.Background(), tc.req, []string{"one", "two"})
+
+			// Then
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("want error containing %q, got %v", tc.wantErr, err)
+			}
+
+			if tc.wantResults && len(results) != 2 {
+				t.Errorf("want an empty result for each subreddit, got %v", results)
+			}
+		})
+	}
+}
+
+func TestPostToSubredditsUploadFails(t *testing.T) {
+	// reddit server. refuses to lease the asset
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+
+	req := PostImageRequest{
+		Path:  "testdata/testimg.jpeg",
+		Title: "image test",
+	}
+
+	// When
+	results, err := reddit.PostToSubreddits(context.Background(), req, []string{"one", "two"})
+
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "uploading asset") {
+		t.Errorf("want an upload error, got %v", err)
+	}
+
+	if len(results) != 2 || results[0].Name != "" || results[1].Name != "" {
+		t.Errorf("want an empty result for each subreddit, got %v", results)
+	}
+}
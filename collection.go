@@ -0,0 +1,94 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// collectionIDPattern matches the UUIDs reddit identifies collections by.
+var collectionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func (c *client) AddToCollection(ctx context.Context, collectionID, fullname string) error {
+	err := validateCollectionID(collectionID)
+	if err != nil {
+		return err
+	}
+
+	err = validateFullname(fullname)
+	if err != nil {
+		return err
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	form := url.Values{
+		"collection_id": []string{collectionID},
+		"link_fullname": []string{fullname},
+	}
+
+	err = c.reddit.PostForm(ctx, "/api/v1/collections/add_post_to_collection", form)
+	if err != nil {
+		return fmt.Errorf("adding %s to collection %s: %w", fullname, collectionID, err)
+	}
+	return nil
+}
+
+// CollectionLayout is how a collection's posts are shown.
+type CollectionLayout string
+
+const (
+	CollectionLayoutTimeline CollectionLayout = "TIMELINE"
+	CollectionLayoutGallery  CollectionLayout = "GALLERY"
+)
+
+func (c *client) CreateCollection(ctx context.Context, subredditFullname, title, description string, layout CollectionLayout) (string, error) {
+	if !strings.HasPrefix(subredditFullname, "t5_") || len(subredditFullname) == len("t5_") {
+		return "", fmt.Errorf("%q is not a subreddit fullname like t5_2qh1i", subredditFullname)
+	}
+
+	if title == "" {
+		return "", fmt.Errorf("must provide a title")
+	}
+
+	if layout != CollectionLayoutTimeline && layout != CollectionLayoutGallery {
+		return "", fmt.Errorf("layout must be TIMELINE or GALLERY")
+	}
+
+	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	form := url.Values{
+		"sr_fullname":    []string{subredditFullname},
+		"title":          []string{title},
+		"description":    []string{description},
+		"display_layout": []string{string(layout)},
+	}
+
+	var collection struct {
+		CollectionID string `json:"collection_id"`
+	}
+	err = c.reddit.PostFormJSON(ctx, "/api/v1/collections/create_collection", form, &collection)
+	if err != nil {
+		return "", fmt.Errorf("creating collection %q: %w", title, err)
+	}
+
+	if collection.CollectionID == "" {
+		return "", fmt.Errorf("creating collection %q: no collection id in response", title)
+	}
+	return collection.CollectionID, nil
+}
+
+func validateCollectionID(collectionID string) error {
+	if !collectionIDPattern.MatchString(collectionID) {
+		return fmt.Errorf("%q is not a collection id like 2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44", collectionID)
+	}
+	return nil
+}
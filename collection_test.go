@@ -0,0 +1,53 @@
+package redmed
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestAddToCollection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Given
+		var requests []*http.Request
+		reddit := newManageTestClient(t, &requests)
+
+		// When
+		err := reddit.AddToCollection(context.Background(), "2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44", "t3_x1qxro")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if len(requests) != 1 {
+			t.Fatalf("want 1 request, got %d", len(requests))
+		}
+
+		assertRequest(t, requests[0], "/api/v1/collections/add_post_to_collection", url.Values{
+			"collection_id": {"2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44"},
+			"link_fullname": {"t3_x1qxro"},
+		})
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			collectionID string
+			fullname     string
+		}{
+			{"CollectionID", "collection", "t3_x1qxro"},
+			{"Fullname", "2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44", "x1qxro"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+				err := reddit.AddToCollection(context.Background(), tc.collectionID, tc.fullname)
+				if err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+	})
+}
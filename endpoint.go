@@ -0,0 +1,70 @@
+package redmed
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func (c *reddit) setBaseURL(rawURL string) {
+	c.baseURL = strings.TrimSuffix(rawURL, "/")
+}
+
+func (c *reddit) setTokenURL(rawURL string) {
+	c.tokenURL = rawURL
+}
+
+func (c *reddit) setInsecureEndpoints(insecure bool) {
+	c.insecureEndpoints = insecure
+}
+
+// apiURL returns the url the reddit api is reached at.
+func (c *reddit) apiURL() string {
+	if c.baseURL == "" {
+		return baseURL
+	}
+	return c.baseURL
+}
+
+// tokenEndpoint returns the url oauth tokens are fetched from.
+func (c *reddit) tokenEndpoint() string {
+	if c.tokenURL == "" {
+		return tokenURL
+	}
+	return c.tokenURL
+}
+
+// validateEndpoints checks the urls given with WithBaseURL and WithTokenURL, once
+// every option is applied so WithInsecureEndpoints may come in any order.
+func (c *reddit) validateEndpoints() error {
+	for _, endpoint := range []struct {
+		option string
+		rawURL string
+	}{
+		{"WithBaseURL", c.baseURL},
+		{"WithTokenURL", c.tokenURL},
+	} {
+		if endpoint.rawURL == "" {
+			continue
+		}
+
+		u, err := url.Parse(endpoint.rawURL)
+		if err != nil {
+			return fmt.Errorf("%s: %w", endpoint.option, err)
+		}
+
+		if u.Host == "" {
+			return fmt.Errorf("%s: %s has no host", endpoint.option, endpoint.rawURL)
+		}
+
+		switch {
+		case u.Scheme == "https":
+		case u.Scheme == "http" && c.insecureEndpoints:
+		case u.Scheme == "http":
+			return fmt.Errorf("%s: %s must be https unless WithInsecureEndpoints is given", endpoint.option, endpoint.rawURL)
+		default:
+			return fmt.Errorf("%s: %s must be an http or https url", endpoint.option, endpoint.rawURL)
+		}
+	}
+	return nil
+}
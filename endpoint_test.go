@@ -0,0 +1,122 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWithEndpoints(t *testing.T) {
+	// websocket server. on a host of its own, as reddit's is
+	var dialed bool
+	wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		dialed = true
+		c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x2dx7f/title/"}}`))
+	}))
+	defer wsSvr.Close()
+
+	// token server. only hands out tokens
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			t.Errorf("want the token fetched from /token, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"access_token": "token"}`))
+	}))
+	defer tokenSvr.Close()
+
+	// reddit server. the api, without the token endpoint
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/submit":
+			fmt.Fprintf(w, `{"json": {"errors": [], "data": {"websocket_url": "ws%s/ws"}}}`, strings.TrimPrefix(wsSvr.URL, "http"))
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// Given
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithBaseURL(redditSvr.URL+"/"),
+		WithTokenURL(tokenSvr.URL+"/token"),
+		WithInsecureEndpoints(true),
+	)
+
+	fields := url.Values{
+		"kind":  {"image"},
+		"sr":    {"subreddit"},
+		"title": {"endpoint test"},
+		"url":   {"https://i.redd.it/hsklj75xrxk91.jpg"},
+	}
+
+	// When
+	name, err := reddit.Submit(context.Background(), fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if name != "t3_x2dx7f" {
+		t.Errorf("want t3_x2dx7f, got %s", name)
+	}
+
+	if !dialed {
+		t.Error("want the websocket reddit returned dialed as is")
+	}
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		tokenURL string
+		insecure bool
+		wantErr  string
+	}{
+		{name: "Default"},
+		{name: "HTTPS", baseURL: "https://oauth.reddit.com", tokenURL: "https://old.reddit.com/api/v1/access_token"},
+		{name: "HTTPInsecure", baseURL: "http://localhost:8080", tokenURL: "http://localhost:8080/token", insecure: true},
+		{name: "HTTPBaseURL", baseURL: "http://localhost:8080", wantErr: "WithBaseURL: http://localhost:8080 must be https unless WithInsecureEndpoints is given"},
+		{name: "HTTPTokenURL", tokenURL: "http://localhost:8080/token", wantErr: "WithTokenURL: http://localhost:8080/token must be https unless WithInsecureEndpoints is given"},
+		{name: "NoHost", baseURL: "https:///api", wantErr: "WithBaseURL: https:///api has no host"},
+		{name: "OtherScheme", tokenURL: "ftp://reddit.com/token", insecure: true, wantErr: "WithTokenURL: ftp://reddit.com/token must be an http or https url"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+			c.setBaseURL(tc.baseURL)
+			c.setTokenURL(tc.tokenURL)
+			c.setInsecureEndpoints(tc.insecure)
+
+			// When
+			err := c.validateEndpoints()
+
+			// Then
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("want no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("want %s, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
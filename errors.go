@@ -0,0 +1,293 @@
+package redmed
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorDetail is one entry of the error list reddit returns with a rejected
+// request, such as SUBREDDIT_NOTALLOWED or IMAGE_ERROR.
+type ErrorDetail struct {
+	Code    string
+	Message string
+	Field   string
+}
+
+// UnmarshalJSON decodes reddit's [code, message, field] error triples.
+func (d *ErrorDetail) UnmarshalJSON(b []byte) error {
+	var parts []interface{}
+	err := json.Unmarshal(b, &parts)
+	if err != nil {
+		return err
+	}
+
+	fields := []*string{&d.Code, &d.Message, &d.Field}
+	for i, part := range parts {
+		if i == len(fields) {
+			break
+		}
+
+		if s, ok := part.(string); ok {
+			*fields[i] = s
+		}
+	}
+	return nil
+}
+
+func (d ErrorDetail) String() string {
+	s := d.Code
+	if d.Message != "" {
+		s = fmt.Sprintf("%s: %s", s, d.Message)
+	}
+
+	if d.Field != "" {
+		s = fmt.Sprintf("%s (%s)", s, d.Field)
+	}
+	return s
+}
+
+// APIError is returned when reddit rejects a request with a list of errors.
+type APIError struct {
+	Errors []ErrorDetail
+
+	statusCode int
+	body       []byte
+}
+
+// StatusCode returns the http status code of the response. Reddit reports a
+// rejection in the body of an otherwise successful response.
+func (e *APIError) StatusCode() int {
+	return e.statusCode
+}
+
+// RawResponse returns the body of the response as reddit sent it.
+func (e *APIError) RawResponse() []byte {
+	return e.body
+}
+
+func (e *APIError) Error() string {
+	details := make([]string, len(e.Errors))
+	for i, d := range e.Errors {
+		details[i] = d.String()
+	}
+	return fmt.Sprintf("reddit rejected the request: %s", strings.Join(details, ", "))
+}
+
+func (e *APIError) hasCode(code string) bool {
+	for _, d := range e.Errors {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// submitError returns the error for a submit reddit rejected with errs: a
+// *RateLimitError when one of them is RATELIMIT, otherwise an *APIError.
+func submitError(errs []ErrorDetail, body []byte) error {
+	apiErr := &APIError{Errors: errs, statusCode: http.StatusOK, body: body}
+	for _, d := range errs {
+		if d.Code == "RATELIMIT" {
+			return &RateLimitError{RetryAfter: parseRetryAfter(d.Message), Err: apiErr}
+		}
+	}
+	return apiErr
+}
+
+// RateLimitError is returned when reddit refuses a submit because the account has
+// posted too much recently. Nothing was posted. RetryAfter is the wait reddit asked
+// for, or 0 if its message didn't say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter == 0 {
+		return fmt.Sprintf("rate limited: %s", e.Err)
+	}
+	return fmt.Sprintf("rate limited, retry after %s: %s", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned when reddit responds with an unexpected status code.
+type StatusError struct {
+	statusCode int
+	body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.statusCode, string(e.body))
+}
+
+// StatusCode returns the http status code of the response.
+func (e *StatusError) StatusCode() int {
+	return e.statusCode
+}
+
+// RawResponse returns the body of the response as reddit sent it.
+func (e *StatusError) RawResponse() []byte {
+	return e.body
+}
+
+// ScopeError is returned when reddit rejects a request because the oauth token
+// lacks the scope it needs. The app's or token request's scopes need to include
+// Required.
+type ScopeError struct {
+	// Required is the scope the request needed, such as submit or modposts, or ""
+	// if it isn't known.
+	Required string
+	// Granted are the space separated scopes the token has.
+	Granted string
+	Err     *StatusError
+}
+
+func (e *ScopeError) Error() string {
+	if e.Required == "" {
+		return fmt.Sprintf("oauth token has insufficient scope (has %s): %s", e.Granted, e.Err)
+	}
+	return fmt.Sprintf("oauth token lacks the %s scope (has %s): %s", e.Required, e.Granted, e.Err)
+}
+
+func (e *ScopeError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the http status code of the response.
+func (e *ScopeError) StatusCode() int {
+	return e.Err.StatusCode()
+}
+
+// RawResponse returns the body of the response as reddit sent it.
+func (e *ScopeError) RawResponse() []byte {
+	return e.Err.RawResponse()
+}
+
+// DuplicateError is returned when a link was already submitted to the subreddit
+// and the request didn't allow resubmitting it. Name and Permalink describe the
+// existing post when it could be found.
+type DuplicateError struct {
+	Subreddit string
+	// URL is the link that was already submitted.
+	URL       string
+	Name      string
+	Permalink string
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s was already submitted to r/%s", e.URL, e.Subreddit)
+	}
+	return fmt.Sprintf("%s was already submitted to r/%s as %s", e.URL, e.Subreddit, e.Name)
+}
+
+// GalleryItem is an item of a gallery post: the media at Path, uploaded as MediaID.
+type GalleryItem struct {
+	Path    string
+	MediaID string
+}
+
+// GalleryError is returned when reddit rejects a gallery post. Rejected holds the
+// indexes of the Items that reddit's errors name, by media id or position, and is
+// empty when the errors are about the post as a whole.
+type GalleryError struct {
+	Items    []GalleryItem
+	Rejected []int
+	Err      *APIError
+}
+
+func (e *GalleryError) Error() string {
+	if len(e.Rejected) == 0 {
+		return e.Err.Error()
+	}
+
+	items := make([]string, len(e.Rejected))
+	for i, index := range e.Rejected {
+		items[i] = fmt.Sprintf("item %d (%s, media_id %s)", index, e.Items[index].Path, e.Items[index].MediaID)
+	}
+	return fmt.Sprintf("%s: rejected %s", e.Err, strings.Join(items, ", "))
+}
+
+func (e *GalleryError) Unwrap() error {
+	return e.Err
+}
+
+// newGalleryError finds the items of a gallery the errors of err refer to. Reddit
+// names an item by its media id in the message or field, or by its position in a
+// field such as items[2].
+func newGalleryError(items []GalleryItem, err *APIError) *GalleryError {
+	rejected := make(map[int]bool)
+	for _, d := range err.Errors {
+		for i, item := range items {
+			if item.MediaID != "" && (strings.Contains(d.Message, item.MediaID) || strings.Contains(d.Field, item.MediaID)) {
+				rejected[i] = true
+			}
+		}
+
+		if !strings.HasPrefix(d.Field, "items") {
+			continue
+		}
+
+		index, convErr := strconv.Atoi(strings.Trim(strings.TrimPrefix(d.Field, "items"), "[]."))
+		if convErr == nil && index >= 0 && index < len(items) {
+			rejected[index] = true
+		}
+	}
+
+	ge := &GalleryError{Items: items, Err: err}
+	for i := range items {
+		if rejected[i] {
+			ge.Rejected = append(ge.Rejected, i)
+		}
+	}
+	return ge
+}
+
+// MediaProcessingError is returned when reddit reports that it failed to process
+// the media of a submitted post. Resubmitting the same media is unlikely to help.
+type MediaProcessingError struct {
+	Reason string
+	// Message is the raw websocket message reddit sent.
+	Message string
+}
+
+func (e *MediaProcessingError) Error() string {
+	return fmt.Sprintf("reddit failed to process media: %s", e.Reason)
+}
+
+// WebsocketError is returned when the websocket reddit reports post success on
+// fails to connect or read. The failure is in the network, not the post, which
+// may still have been created.
+type WebsocketError struct {
+	Err error
+}
+
+func (e *WebsocketError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *WebsocketError) Unwrap() error {
+	return e.Err
+}
+
+// UploadTimeoutError is returned when uploading media takes longer than
+// WithUploadTimeout allows. Nothing has been submitted yet.
+type UploadTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *UploadTimeoutError) Error() string {
+	return fmt.Sprintf("upload timed out after %s: %s", e.Timeout, e.Err)
+}
+
+func (e *UploadTimeoutError) Unwrap() error {
+	return e.Err
+}
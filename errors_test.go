@@ -0,0 +1,68 @@
+package redmed
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestErrorDetailUnmarshalJSON(t *testing.T) {
+	// Given
+	b := []byte(`[["SUBREDDIT_NOTALLOWED", "you aren't allowed to post there.", "sr"], ["IMAGE_ERROR", "image is too big", null]]`)
+
+	// When
+	var details []ErrorDetail
+	err := json.Unmarshal(b, &details)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	want := []ErrorDetail{
+		{Code: "SUBREDDIT_NOTALLOWED", Message: "you aren't allowed to post there.", Field: "sr"},
+		{Code: "IMAGE_ERROR", Message: "image is too big"},
+	}
+
+	if len(details) != len(want) {
+		t.Fatalf("want %v, got %v", want, details)
+	}
+
+	for i := range want {
+		if details[i] != want[i] {
+			t.Errorf("want %v, got %v", want[i], details[i])
+		}
+	}
+
+	wantErr := "reddit rejected the request: SUBREDDIT_NOTALLOWED: you aren't allowed to post there. (sr), IMAGE_ERROR: image is too big"
+	if got := (&APIError{Errors: details}).Error(); got != wantErr {
+		t.Errorf("want %s, got %s", wantErr, got)
+	}
+}
+
+func TestNewGalleryError(t *testing.T) {
+	items := []GalleryItem{{Path: "a.jpeg", MediaID: "abc"}, {Path: "b.jpeg", MediaID: "def"}}
+
+	tests := []struct {
+		name   string
+		detail ErrorDetail
+		want   []int
+	}{
+		{"MediaIDInMessage", ErrorDetail{Code: "INVALID_MEDIA", Message: "media def is too small", Field: "items"}, []int{1}},
+		{"MediaIDInField", ErrorDetail{Code: "INVALID_MEDIA", Field: "abc"}, []int{0}},
+		{"Index", ErrorDetail{Code: "INVALID_MEDIA", Field: "items[1]"}, []int{1}},
+		{"IndexOutOfRange", ErrorDetail{Code: "INVALID_MEDIA", Field: "items.5"}, nil},
+		{"WholePost", ErrorDetail{Code: "SUBREDDIT_NOTALLOWED", Field: "sr"}, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When
+			got := newGalleryError(items, &APIError{Errors: []ErrorDetail{tc.detail}})
+
+			// Then
+			if fmt.Sprint(got.Rejected) != fmt.Sprint(tc.want) {
+				t.Errorf("want %v rejected, got %v", tc.want, got.Rejected)
+			}
+		})
+	}
+}
@@ -22,51 +22,51 @@ func main() {
 
 	// post .png, .jpg, .jpeg, or .gif image from local path
 	imgReq := redmed.PostImageRequest{
-		NSWF:        false,
+		NSWF:        redmed.Bool(false),
 		Path:        "/path/to/image.jpeg", // change me
-		Resubmit:    true,
-		SendReplies: true,
+		Resubmit:    redmed.Bool(true),
+		SendReplies: redmed.Bool(true),
 		Spoiler:     false,
 		Subreddit:   subreddit,
 		Title:       "image from local path",
 	}
-	name, err := reddit.PostImage(context.Background(), imgReq)
+	result, err := reddit.PostImage(context.Background(), imgReq)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	fmt.Println(name)
+	fmt.Println(result.Name)
 
 	// post .png, .jpg, .jpeg, or .gif image from link
 	imgReq = redmed.PostImageRequest{
-		NSWF:        false,
+		NSWF:        redmed.Bool(false),
 		Path:        "https://host.com/image.jpeg", // change me
-		Resubmit:    true,
-		SendReplies: true,
+		Resubmit:    redmed.Bool(true),
+		SendReplies: redmed.Bool(true),
 		Spoiler:     false,
 		Subreddit:   subreddit,
 		Title:       "image from link",
 	}
 
-	name, err = reddit.PostImage(context.Background(), imgReq)
+	result, err = reddit.PostImage(context.Background(), imgReq)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	fmt.Println(name)
+	fmt.Println(result.Name)
 
 	// post gallery of .png, .jpg, .jpeg, or .gif images from local paths and/or links
 	galReq := redmed.PostGalleryRequest{
-		NSWF:        false,
+		NSWF:        redmed.Bool(false),
 		Paths:       []string{"/path/to/image.jpeg", "https://host.com/image.jpeg"}, // change me
-		SendReplies: true,
+		SendReplies: redmed.Bool(true),
 		Spoiler:     false,
 		Subreddit:   subreddit,
 		Title:       "gallery from local path and link",
 	}
-	name, err = reddit.PostGallery(context.Background(), galReq)
+	name, err := reddit.PostGallery(context.Background(), galReq)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -77,44 +77,44 @@ func main() {
 	// post .mp4 or .mov video from link
 	// must provide image ThumbnailPath (local path or link)
 	req := redmed.PostVideoRequest{
-		Kind:          "video", // or videogif
-		NSWF:          false,
+		Kind:          redmed.VideoKindVideo, // or redmed.VideoKindGif
+		NSWF:          redmed.Bool(false),
 		VideoPath:     "https://host.com/somevideo.mp4", // change me
-		Resubmit:      true,
-		SendReplies:   true,
+		Resubmit:      redmed.Bool(true),
+		SendReplies:   redmed.Bool(true),
 		Spoiler:       false,
 		Subreddit:     subreddit,
 		Title:         "video from link",
 		ThumbnailPath: "https://host.com/image.jpeg", // change me
 	}
 
-	name, err = reddit.PostVideo(context.Background(), req)
+	result, err = reddit.PostVideo(context.Background(), req)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	fmt.Println(name)
+	fmt.Println(result.Name)
 
 	// post .mp4 or .mov video from local path
 	// must provide image ThumbnailPath (local path or link)
 	req = redmed.PostVideoRequest{
-		Kind:          "video",
-		NSWF:          false,
+		Kind:          redmed.VideoKindVideo,
+		NSWF:          redmed.Bool(false),
 		VideoPath:     "/path/to/video.mp4", // change me
-		Resubmit:      true,
-		SendReplies:   true,
+		Resubmit:      redmed.Bool(true),
+		SendReplies:   redmed.Bool(true),
 		Spoiler:       false,
 		Subreddit:     subreddit,
 		Title:         "video from local path",
 		ThumbnailPath: "https://host.com/image.jpeg", // change me
 	}
 
-	name, err = reddit.PostVideo(context.Background(), req)
+	result, err = reddit.PostVideo(context.Background(), req)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	fmt.Println(name)
+	fmt.Println(result.Name)
 }
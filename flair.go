@@ -0,0 +1,139 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Flair is a post flair a subreddit offers.
+type Flair struct {
+	// ID is what a post request's FlairID takes.
+	ID   string
+	Text string
+	// TextEditable is true when the post's FlairText can replace Text.
+	TextEditable bool
+}
+
+type flairData struct {
+	ID           string `json:"id"`
+	Text         string `json:"text"`
+	TextEditable bool   `json:"text_editable"`
+}
+
+type postRequirements struct {
+	IsFlairRequired bool `json:"is_flair_required"`
+}
+
+func (c *client) ListFlairs(ctx context.Context, subreddit string) ([]Flair, error) {
+	subreddit = normalizeSubreddit(subreddit)
+
+	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	flairs, err := c.reddit.LinkFlairs(ctx, subreddit)
+	if err != nil {
+		return nil, fmt.Errorf("getting flairs of r/%s: %w", subreddit, err)
+	}
+	return append([]Flair(nil), flairs...), nil
+}
+
+// LinkFlairs returns the post flairs of subreddit, fetched anew, and caches them
+// for cachedLinkFlairs.
+func (c *reddit) LinkFlairs(ctx context.Context, subreddit string) ([]Flair, error) {
+	var data []flairData
+	err := c.GetJSON(ctx, fmt.Sprintf("/r/%s/api/link_flair_v2", url.PathEscape(subreddit)), nil, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	flairs := make([]Flair, len(data))
+	for i, d := range data {
+		flairs[i] = Flair{ID: d.ID, Text: d.Text, TextEditable: d.TextEditable}
+	}
+
+	c.flairsMu.Lock()
+	defer c.flairsMu.Unlock()
+	if c.flairs == nil {
+		c.flairs = make(map[string][]Flair)
+	}
+	c.flairs[subreddit] = flairs
+
+	return flairs, nil
+}
+
+// cachedLinkFlairs returns the post flairs of subreddit, only fetching them the
+// first time for the life of the client, or after ListFlairs refreshes them.
+func (c *reddit) cachedLinkFlairs(ctx context.Context, subreddit string) ([]Flair, error) {
+	c.flairsMu.Lock()
+	flairs, ok := c.flairs[subreddit]
+	c.flairsMu.Unlock()
+	if ok {
+		return flairs, nil
+	}
+	return c.LinkFlairs(ctx, subreddit)
+}
+
+// flairByName returns the id of the flair of subreddit whose text is name,
+// ignoring case.
+func (c *client) flairByName(ctx context.Context, subreddit, name string) (string, error) {
+	flairs, err := c.reddit.cachedLinkFlairs(ctx, subreddit)
+	if err != nil {
+		return "", fmt.Errorf("getting flairs of r/%s: %w", subreddit, err)
+	}
+
+	texts := make([]string, len(flairs))
+	for i, f := range flairs {
+		if strings.EqualFold(f.Text, name) {
+			return f.ID, nil
+		}
+		texts[i] = fmt.Sprintf("%q", f.Text)
+	}
+
+	if len(flairs) == 0 {
+		return "", fmt.Errorf("r/%s has no flair %q; it offers none", subreddit, name)
+	}
+	return "", fmt.Errorf("r/%s has no flair %q; it offers %s", subreddit, name, strings.Join(texts, ", "))
+}
+
+// checkFlair returns the flair id of a post to subreddit: flairID, or the id of
+// the flair named flairName. With WithFlairPrecheck, it checks that flairID is one
+// the subreddit offers, and that a post without one isn't to a subreddit that
+// requires flair.
+func (c *client) checkFlair(ctx context.Context, subreddit, flairID, flairName string) (string, error) {
+	if flairName != "" {
+		return c.flairByName(ctx, subreddit, flairName)
+	}
+
+	if !c.flairPrecheck {
+		return flairID, nil
+	}
+
+	if flairID == "" {
+		var reqs postRequirements
+		err := c.reddit.GetJSON(ctx, fmt.Sprintf("/api/v1/%s/post_requirements", url.PathEscape(subreddit)), nil, &reqs)
+		if err != nil {
+			return "", fmt.Errorf("getting post requirements of r/%s: %w", subreddit, err)
+		}
+
+		if reqs.IsFlairRequired {
+			return "", fmt.Errorf("r/%s requires flair: set FlairID to one from ListFlairs, or FlairName", subreddit)
+		}
+		return "", nil
+	}
+
+	flairs, err := c.reddit.cachedLinkFlairs(ctx, subreddit)
+	if err != nil {
+		return "", fmt.Errorf("getting flairs of r/%s: %w", subreddit, err)
+	}
+
+	for _, f := range flairs {
+		if f.ID == flairID {
+			return flairID, nil
+		}
+	}
+	return "", fmt.Errorf("r/%s has no flair %s", subreddit, flairID)
+}
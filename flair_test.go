@@ -0,0 +1,150 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFlairTestClient returns a client talking to a reddit server for a subreddit
+// that requires one of two flairs. Uploading media fails the test.
+func newFlairTestClient(t *testing.T, options ...Option) Client {
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/r/subreddit/api/link_flair_v2":
+			w.Write([]byte(`[{"id": "c0ffee00-0000-0000-0000-000000000001", "text": "OC", "text_editable": false}, {"id": "c0ffee00-0000-0000-0000-000000000002", "text": "Meta", "text_editable": true}]`))
+		case "/api/v1/subreddit/post_requirements":
+			w.Write([]byte(`{"is_flair_required": true}`))
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	t.Cleanup(redditSvr.Close)
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	t.Cleanup(func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	})
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	return New("userAgent", "clientID", "secret", "username", "password", append([]Option{WithHTTPClient(httpClient)}, options...)...)
+}
+
+func TestListFlairs(t *testing.T) {
+	// Given
+	reddit := newFlairTestClient(t)
+
+	// When
+	flairs, err := reddit.ListFlairs(context.Background(), "subreddit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	want := []Flair{
+		{ID: "c0ffee00-0000-0000-0000-000000000001", Text: "OC"},
+		{ID: "c0ffee00-0000-0000-0000-000000000002", Text: "Meta", TextEditable: true},
+	}
+
+	if len(flairs) != len(want) {
+		t.Fatalf("want %v, got %v", want, flairs)
+	}
+
+	for i := range want {
+		if flairs[i] != want[i] {
+			t.Errorf("want %v, got %v", want[i], flairs[i])
+		}
+	}
+}
+
+func TestFlairPrecheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		flairID string
+		wantErr string
+	}{
+		{"Required", "", "r/subreddit requires flair"},
+		{"Unknown", "c0ffee00-0000-0000-0000-000000000003", "r/subreddit has no flair c0ffee00-0000-0000-0000-000000000003"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			reddit := newFlairTestClient(t, WithFlairPrecheck(true))
+
+			req := PostImageRequest{
+				FlairID:   tc.flairID,
+				Path:      "testdata/testimg.jpeg",
+				Subreddit: "subreddit",
+				Title:     "image test",
+			}
+
+			// When
+			_, err := reddit.PostImage(context.Background(), req)
+
+			// Then
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("want %s, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFlairByName(t *testing.T) {
+	tests := []struct {
+		name      string
+		flairName string
+		wantID    string
+		wantErr   string
+	}{
+		{"IgnoresCase", "meta", "c0ffee00-0000-0000-0000-000000000002", ""},
+		{"Unknown", "News", "", `r/subreddit has no flair "News"; it offers "OC", "Meta"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			c := newFlairTestClient(t).(*client)
+
+			// When
+			id, err := c.checkFlair(context.Background(), "subreddit", "", tc.flairName)
+
+			// Then
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("want %s, got %v", tc.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if id != tc.wantID {
+				t.Errorf("want %s, got %s", tc.wantID, id)
+			}
+
+			if len(c.reddit.flairs["subreddit"]) != 2 {
+				t.Error("want the flairs of r/subreddit cached")
+			}
+		})
+	}
+}
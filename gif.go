@@ -0,0 +1,79 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// extractGIFThumbnail writes the first frame of the gif at path in fsys to a png in
+// the temp dir and returns its path. The caller removes the file.
+func (c *reddit) extractGIFThumbnail(fsys fs.FS, path string) (string, error) {
+	if c.tempDirErr != nil {
+		return "", c.tempDirErr
+	}
+
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// gif.Decode returns only the first frame
+	frame, err := gif.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("decoding gif %s: %w", path, err)
+	}
+
+	thumbnail, err := os.CreateTemp(c.tempDir, "redmed*.png")
+	if err != nil {
+		return "", err
+	}
+
+	err = png.Encode(thumbnail, frame)
+	if closeErr := thumbnail.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(thumbnail.Name())
+		return "", fmt.Errorf("encoding thumbnail: %w", err)
+	}
+
+	return thumbnail.Name(), nil
+}
+
+// mediaSize returns the size in bytes of the media at path, a local path or link,
+// or -1 if it isn't known. A link's size is what its host reports for a HEAD
+// request, which not every host answers.
+func (c *reddit) mediaSize(ctx context.Context, path string) (int64, error) {
+	if !isValidURL(path) {
+		info, err := fs.Stat(c.files(), path)
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodHead, path, nil)
+	if err != nil {
+		return 0, err
+	}
+	r.Header.Set("User-Agent", c.downloadUserAgent())
+
+	resp, err := c.doWith(c.downloadClient(), r)
+	if err != nil {
+		// the download reports the link's problems, if it has any
+		return -1, nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1, nil
+	}
+	return resp.ContentLength, nil
+}
@@ -0,0 +1,339 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestGIF writes a two frame gif, red then blue, to dir.
+func writeTestGIF(t *testing.T, dir string) string {
+	t.Helper()
+
+	palette := color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	g := &gif.GIF{}
+	for i := range palette {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		for p := range frame.Pix {
+			frame.Pix[p] = uint8(i)
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+
+	path := filepath.Join(dir, "test.gif")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	err = gif.EncodeAll(f, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractGIFThumbnail(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setTempDir(dir)
+
+	// When
+	path, err := c.extractGIFThumbnail(osFS{}, writeTestGIF(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if filepath.Dir(path) != dir || filepath.Ext(path) != ".png" {
+		t.Errorf("want a png in %s, got %s", dir, path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	thumbnail, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, g, b, _ := thumbnail.At(0, 0).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Errorf("want the first, red frame, got %d %d %d", r, g, b)
+	}
+}
+
+func TestPostVideoGIFWithoutThumbnail(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. records the leased mime types and the submitted kind
+	var mimeTypes []string
+	var kind string
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			mimeTypes = append(mimeTypes, r.PostForm.Get("mimetype"))
+
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			err = json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			kind = r.PostForm.Get("kind")
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	tempDir := t.TempDir()
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithAsyncSubmit(true),
+		WithTempDir(tempDir),
+	)
+
+	req := PostVideoRequest{
+		VideoPath: writeTestGIF(t, t.TempDir()),
+		Subreddit: "subreddit",
+		Title:     "gif test",
+	}
+
+	// When
+	_, err = reddit.PostVideo(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if kind != "videogif" {
+		t.Errorf("want kind videogif, got %s", kind)
+	}
+
+	if len(mimeTypes) != 2 || mimeTypes[0] != "image/gif" || mimeTypes[1] != "image/png" {
+		t.Errorf("want a gif and png thumbnail uploaded, got %v", mimeTypes)
+	}
+
+	assertEmptyDir(t, tempDir)
+}
+
+func TestPostImageLargeGIF(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. records the leased mime types and the submitted kind
+	var mimeTypes []string
+	var kind string
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			mimeTypes = append(mimeTypes, r.PostForm.Get("mimetype"))
+
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			err = json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			kind = r.PostForm.Get("kind")
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		size          int64
+		wantKind      string
+		wantMimeTypes []string
+	}{
+		{"Small", 0, "image", []string{"image/gif"}},
+		{"Large", maxImageSize + 1, "videogif", []string{"image/gif", "image/png"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			mimeTypes, kind = nil, ""
+
+			path := writeTestGIF(t, t.TempDir())
+			if tc.size > 0 {
+				// the gif decodes the same with the padding after it
+				err := os.Truncate(path, tc.size)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reddit := New("userAgent", "clientID", "secret", "username", "password",
+				WithHTTPClient(httpClient),
+				WithAsyncSubmit(true),
+			)
+
+			req := PostImageRequest{
+				Path:      path,
+				Subreddit: "subreddit",
+				Title:     "gif test",
+			}
+
+			// When
+			result, err := reddit.PostImage(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if kind != tc.wantKind {
+				t.Errorf("want kind %s, got %s", tc.wantKind, kind)
+			}
+
+			if strings.Join(mimeTypes, ",") != strings.Join(tc.wantMimeTypes, ",") {
+				t.Errorf("want %v uploaded, got %v", tc.wantMimeTypes, mimeTypes)
+			}
+
+			if tc.wantKind == "videogif" && result.ThumbnailAssetID == "" {
+				t.Error("want the extracted thumbnail in the result")
+			}
+		})
+	}
+}
+
+func TestMediaSize(t *testing.T) {
+	// link server. reports the size of a gif without sending it
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("want a HEAD request, got %s", r.Method)
+		}
+
+		if r.URL.Path == "/missing.gif" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", "1234")
+	}))
+	defer linkSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+	// When
+	size, err := c.mediaSize(context.Background(), linkSvr.URL+"/image.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	missingSize, err := c.mediaSize(context.Background(), linkSvr.URL+"/missing.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if size != 1234 {
+		t.Errorf("want size 1234, got %d", size)
+	}
+
+	if missingSize != -1 {
+		t.Errorf("want an unknown size for a missing link, got %d", missingSize)
+	}
+}
@@ -0,0 +1,183 @@
+package redmed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// recentSubmit is a post the client submitted within the duplicate protection
+// window. name is empty until reddit confirms the post was created.
+type recentSubmit struct {
+	name string
+	at   time.Time
+}
+
+// submitKey identifies a post by what it submits, so a retry of the same request
+// maps to the same key.
+func submitKey(kind, subreddit, title string, paths ...string) string {
+	h := sha256.New()
+	for _, s := range append([]string{kind, strings.ToLower(subreddit), title}, paths...) {
+		io.WriteString(h, s)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// findRecentSubmit returns the fullname of a post already submitted for key within
+// the duplicate protection window. A submit that was sent but never confirmed is
+// looked up among the user's newest posts.
+func (c *client) findRecentSubmit(ctx context.Context, key, subreddit, title string) (string, bool, error) {
+	if c.duplicateWindow <= 0 {
+		return "", false, nil
+	}
+
+	c.recentMu.Lock()
+	rs, ok := c.recent[key]
+	c.recentMu.Unlock()
+
+	if !ok || time.Since(rs.at) > c.duplicateWindow {
+		return "", false, nil
+	}
+
+	if rs.name != "" {
+		return rs.name, true, nil
+	}
+
+	name, err := c.findOwnPost(ctx, subreddit, title, rs.at)
+	if err != nil {
+		return "", false, fmt.Errorf("checking for an earlier submission: %w", err)
+	}
+
+	if name == "" {
+		return "", false, nil
+	}
+
+	c.recordSubmit(key, recentSubmit{name: name, at: rs.at})
+	return name, true, nil
+}
+
+// findOwnPost returns the fullname of the user's post with title in subreddit
+// created since, or "" if there isn't one.
+func (c *client) findOwnPost(ctx context.Context, subreddit, title string, since time.Time) (string, error) {
+	var l listing
+	query := url.Values{"sort": []string{"new"}, "limit": []string{"25"}}
+	err := c.reddit.GetJSON(ctx, fmt.Sprintf("/user/%s/submitted", c.reddit.user()), query, &l)
+	if err != nil {
+		return "", err
+	}
+
+	// created_utc is only precise to the second and the clocks may disagree
+	since = since.Add(-time.Minute)
+	for _, child := range l.Data.Children {
+		s := child.Data.submission()
+		if s.Title == title && strings.EqualFold(s.Subreddit, subreddit) && !s.CreatedUTC.Before(since) {
+			return s.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *client) recordSubmit(key string, rs recentSubmit) {
+	c.recentMu.Lock()
+	defer c.recentMu.Unlock()
+
+	if c.recent == nil {
+		c.recent = make(map[string]recentSubmit)
+	}
+
+	for k, v := range c.recent {
+		if time.Since(v.at) > c.duplicateWindow {
+			delete(c.recent, k)
+		}
+	}
+	c.recent[key] = rs
+}
+
+func (c *client) forgetSubmit(key string) {
+	c.recentMu.Lock()
+	defer c.recentMu.Unlock()
+	delete(c.recent, key)
+}
+
+// submitPost submits a post through /api/submit, remembering it under key when
+// duplicate protection is on. A submit that fails on the websocket may still have
+// created the post, so it is remembered as unconfirmed rather than forgotten.
+func (c *client) submitPost(ctx context.Context, key, websocketURL string, form url.Values) (string, string, error) {
+	if c.duplicateWindow <= 0 || c.reddit.dryRun {
+		return c.submit(ctx, websocketURL, form)
+	}
+
+	at := time.Now()
+	c.recordSubmit(key, recentSubmit{at: at})
+
+	name, websocketURL, err := c.submit(ctx, websocketURL, form)
+	if err != nil {
+		var wsErr *WebsocketError
+		if !errors.As(err, &wsErr) {
+			c.forgetSubmit(key)
+		}
+		return "", "", err
+	}
+
+	c.recordSubmit(key, recentSubmit{name: name, at: at})
+	return name, websocketURL, nil
+}
+
+// submit submits a post through /api/submit, returning its fullname and the url
+// of the websocket it was waited for on. A link reddit rejects as already
+// submitted is returned as a *DuplicateError naming the existing post.
+func (c *client) submit(ctx context.Context, websocketURL string, fields url.Values) (string, string, error) {
+	form := url.Values{}
+	for k, v := range fields {
+		form[k] = v
+	}
+	form.Set("api_type", "json")
+
+	name, websocketURL, err := c.reddit.SubmitPost(ctx, websocketURL, strings.NewReader(form.Encode()))
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.hasCode("ALREADY_SUB") {
+		return "", "", c.duplicateError(ctx, form.Get("sr"), form.Get("url"))
+	}
+	return name, websocketURL, err
+}
+
+// duplicateError looks up the post link was already submitted as in subreddit.
+func (c *client) duplicateError(ctx context.Context, subreddit, link string) *DuplicateError {
+	dupErr := &DuplicateError{Subreddit: subreddit, URL: link}
+
+	s, err := c.findLinkPost(ctx, subreddit, link)
+	if err != nil || s == nil {
+		// the duplicate is what the caller needs to know about, found or not
+		return dupErr
+	}
+
+	dupErr.Name = s.Name
+	dupErr.Permalink = s.Permalink
+	return dupErr
+}
+
+// findLinkPost returns the post of link in subreddit, or nil if link hasn't been
+// posted there.
+func (c *client) findLinkPost(ctx context.Context, subreddit, link string) (*Submission, error) {
+	var l listing
+	err := c.reddit.GetJSON(ctx, "/api/info", url.Values{"url": []string{link}}, &l)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range l.Data.Children {
+		if strings.EqualFold(child.Data.Subreddit, subreddit) {
+			s := child.Data.submission()
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,201 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDuplicateProtection(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. accepts the submit, but its websocket can't be dialed, so the
+	// post is never confirmed
+	var submits int
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			alr.Asset.WebsocketURL = "wss://127.0.0.1:1"
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			submits++
+		case "/user/username/submitted":
+			fmt.Fprintf(w, `{"data": {"children": [
+				{"data": {"name": "t3_old", "title": "image test", "subreddit": "subreddit", "created_utc": %d}},
+				{"data": {"name": "t3_x1qxro", "title": "image test", "subreddit": "subreddit", "created_utc": %d}}
+			]}}`, time.Now().Add(-time.Hour).Unix(), time.Now().Unix())
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithDuplicateProtection(time.Hour),
+	)
+
+	req := PostImageRequest{
+		Path:      "testdata/testimg.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	_, err = reddit.PostImage(context.Background(), req)
+
+	var wsErr *WebsocketError
+	if !errors.As(err, &wsErr) {
+		t.Fatalf("want *WebsocketError, got %v", err)
+	}
+
+	// When
+	result, err := reddit.PostImage(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if result.Name != "t3_x1qxro" {
+		t.Errorf("want t3_x1qxro, got %s", result.Name)
+	}
+
+	if submits != 1 {
+		t.Errorf("want 1 submit, got %d", submits)
+	}
+}
+
+func TestAlreadySubmitted(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. rejects the submit as a duplicate and lists the existing post
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			alr.Asset.WebsocketURL = "wss://127.0.0.1:1"
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := r.PostForm.Get("api_type"); got != "json" {
+				t.Errorf("want api_type json, got %s", got)
+			}
+			w.Write([]byte(`{"json": {"errors": [["ALREADY_SUB", "that link has already been submitted", "url"]]}}`))
+		case "/api/info":
+			if got := r.URL.Query().Get("url"); got != "https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91" {
+				t.Errorf("want the uploaded url, got %s", got)
+			}
+			w.Write([]byte(`{"data": {"children": [
+				{"data": {"name": "t3_other", "subreddit": "other", "permalink": "/r/other/comments/other/image_test/"}},
+				{"data": {"name": "t3_x1qxro", "subreddit": "subreddit", "permalink": "/r/subreddit/comments/x1qxro/image_test/"}}
+			]}}`))
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+
+	req := PostImageRequest{
+		Path:      "testdata/testimg.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	// When
+	_, err = reddit.PostImage(context.Background(), req)
+
+	// Then
+	var dupErr *DuplicateError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("want *DuplicateError, got %v", err)
+	}
+
+	if dupErr.Name != "t3_x1qxro" || dupErr.Permalink != "/r/subreddit/comments/x1qxro/image_test/" {
+		t.Errorf("want t3_x1qxro, got %+v", dupErr)
+	}
+}
@@ -0,0 +1,108 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func (c *client) MarkNSFW(ctx context.Context, fullname string) error {
+	return c.postAction(ctx, "/api/marknsfw", fullname, nil)
+}
+
+func (c *client) UnmarkNSFW(ctx context.Context, fullname string) error {
+	return c.postAction(ctx, "/api/unmarknsfw", fullname, nil)
+}
+
+func (c *client) MarkSpoiler(ctx context.Context, fullname string) error {
+	return c.postAction(ctx, "/api/spoiler", fullname, nil)
+}
+
+func (c *client) UnmarkSpoiler(ctx context.Context, fullname string) error {
+	return c.postAction(ctx, "/api/unspoiler", fullname, nil)
+}
+
+func (c *client) Sticky(ctx context.Context, fullname string, state bool, slot int) error {
+	if slot != 1 && slot != 2 {
+		return fmt.Errorf("slot must be 1 or 2")
+	}
+
+	form := url.Values{
+		"api_type": []string{"json"},
+		"state":    []string{strconv.FormatBool(state)},
+		"num":      []string{strconv.Itoa(slot)},
+	}
+	return c.postAction(ctx, "/api/set_subreddit_sticky", fullname, form)
+}
+
+func (c *client) Distinguish(ctx context.Context, fullname, how string) error {
+	switch how {
+	case "yes", "no", "admin", "special":
+	default:
+		return fmt.Errorf("how must be yes, no, admin, or special")
+	}
+
+	form := url.Values{
+		"api_type": []string{"json"},
+		"how":      []string{how},
+	}
+	return c.postAction(ctx, "/api/distinguish", fullname, form)
+}
+
+func (c *client) SetSuggestedSort(ctx context.Context, fullname, sort string) error {
+	switch sort {
+	case "confidence", "top", "new", "controversial", "old", "random", "qa", "live":
+	case "":
+		// reddit clears the suggested sort with blank
+		sort = "blank"
+	default:
+		return fmt.Errorf("sort must be confidence, top, new, controversial, old, random, qa, or live")
+	}
+
+	form := url.Values{
+		"api_type": []string{"json"},
+		"sort":     []string{sort},
+	}
+	return c.postAction(ctx, "/api/set_suggested_sort", fullname, form)
+}
+
+func (c *client) SetContestMode(ctx context.Context, fullname string, enabled bool) error {
+	form := url.Values{
+		"api_type": []string{"json"},
+		"state":    []string{strconv.FormatBool(enabled)},
+	}
+	return c.postAction(ctx, "/api/set_contest_mode", fullname, form)
+}
+
+// postAction posts form, with the id of the post named by fullname, to endpoint.
+func (c *client) postAction(ctx context.Context, endpoint string, fullname string, form url.Values) error {
+	err := validateFullname(fullname)
+	if err != nil {
+		return err
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	if form == nil {
+		form = url.Values{}
+	}
+	form.Set("id", fullname)
+
+	err = c.reddit.PostForm(ctx, endpoint, form)
+	if err != nil {
+		return fmt.Errorf("updating %s: %w", fullname, err)
+	}
+	return nil
+}
+
+func validateFullname(fullname string) error {
+	if !strings.HasPrefix(fullname, "t3_") || len(fullname) == len("t3_") {
+		return fmt.Errorf("%q is not a post fullname like t3_x1qxro", fullname)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newManageTestClient returns a client talking to a reddit server that records
+// the path and form of every api request.
+func newManageTestClient(t *testing.T, requests *[]*http.Request) Client {
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			resp := token{AccessToken: "token"}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b)
+		default:
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			*requests = append(*requests, r)
+			w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(redditSvr.Close)
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	t.Cleanup(func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	})
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	return New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+}
+
+func TestMarkPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		mark     func(Client, context.Context, string) error
+	}{
+		{"MarkNSFW", "/api/marknsfw", Client.MarkNSFW},
+		{"UnmarkNSFW", "/api/unmarknsfw", Client.UnmarkNSFW},
+		{"MarkSpoiler", "/api/spoiler", Client.MarkSpoiler},
+		{"UnmarkSpoiler", "/api/unspoiler", Client.UnmarkSpoiler},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			var requests []*http.Request
+			reddit := newManageTestClient(t, &requests)
+
+			// When
+			err := tc.mark(reddit, context.Background(), "t3_x1qxro")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if len(requests) != 1 {
+				t.Fatalf("want 1 request, got %d", len(requests))
+			}
+
+			assertRequest(t, requests[0], tc.endpoint, url.Values{"id": {"t3_x1qxro"}})
+		})
+	}
+
+	t.Run("InvalidFullname", func(t *testing.T) {
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		err := reddit.MarkNSFW(context.Background(), "x1qxro")
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSticky(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Given
+		var requests []*http.Request
+		reddit := newManageTestClient(t, &requests)
+
+		// When
+		err := reddit.Sticky(context.Background(), "t3_x1qxro", true, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if len(requests) != 1 {
+			t.Fatalf("want 1 request, got %d", len(requests))
+		}
+
+		assertRequest(t, requests[0], "/api/set_subreddit_sticky", url.Values{
+			"id":    {"t3_x1qxro"},
+			"state": {"true"},
+			"num":   {"2"},
+		})
+	})
+	t.Run("InvalidSlot", func(t *testing.T) {
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		err := reddit.Sticky(context.Background(), "t3_x1qxro", true, 3)
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestDistinguish(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Given
+		var requests []*http.Request
+		reddit := newManageTestClient(t, &requests)
+
+		// When
+		err := reddit.Distinguish(context.Background(), "t3_x1qxro", "yes")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if len(requests) != 1 {
+			t.Fatalf("want 1 request, got %d", len(requests))
+		}
+
+		assertRequest(t, requests[0], "/api/distinguish", url.Values{
+			"id":  {"t3_x1qxro"},
+			"how": {"yes"},
+		})
+	})
+	t.Run("InvalidHow", func(t *testing.T) {
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		err := reddit.Distinguish(context.Background(), "t3_x1qxro", "moderator")
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func assertRequest(t *testing.T, r *http.Request, path string, form url.Values) {
+	t.Helper()
+
+	if r.URL.Path != path {
+		t.Errorf("want request to %s, got %s", path, r.URL.Path)
+	}
+
+	for k := range form {
+		if r.PostForm.Get(k) != form.Get(k) {
+			t.Errorf("want %s=%s, got %s", k, form.Get(k), r.PostForm.Get(k))
+		}
+	}
+}
+
+func TestSetSuggestedSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		wantSort string
+	}{
+		{"Sort", "qa", "qa"},
+		{"Clear", "", "blank"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			var requests []*http.Request
+			reddit := newManageTestClient(t, &requests)
+
+			// When
+			err := reddit.SetSuggestedSort(context.Background(), "t3_x1qxro", tc.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if len(requests) != 1 {
+				t.Fatalf("want 1 request, got %d", len(requests))
+			}
+
+			assertRequest(t, requests[0], "/api/set_suggested_sort", url.Values{
+				"id":   {"t3_x1qxro"},
+				"sort": {tc.wantSort},
+			})
+		})
+	}
+	t.Run("InvalidSort", func(t *testing.T) {
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		err := reddit.SetSuggestedSort(context.Background(), "t3_x1qxro", "best")
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSetContestMode(t *testing.T) {
+	// Given
+	var requests []*http.Request
+	reddit := newManageTestClient(t, &requests)
+
+	// When
+	err := reddit.SetContestMode(context.Background(), "t3_x1qxro", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if len(requests) != 1 {
+		t.Fatalf("want 1 request, got %d", len(requests))
+	}
+
+	assertRequest(t, requests[0], "/api/set_contest_mode", url.Values{
+		"id":    {"t3_x1qxro"},
+		"state": {"true"},
+	})
+}
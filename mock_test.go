@@ -0,0 +1,430 @@
+package redmed_test
+
+// These tests post against redmedtest's fake reddit. It imports redmed, so they
+// are in the external test package.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atye/redmed"
+	"github.com/atye/redmed/redmedtest"
+)
+
+func TestPostGallerySubmitErrorNamesItem(t *testing.T) {
+	// mock reddit. rejects the second item of the gallery
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	mock.Handle("/api/submit_gallery_post.json", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Items []map[string]string `json:"items"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		fmt.Fprintf(w, `{"json": {"errors": [["INVALID_MEDIA", "media %s is too small", "items"]]}}`, payload.Items[1]["media_id"])
+	})
+
+	// Given
+	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+	req := redmed.PostGalleryRequest{
+		Paths:     []string{"testdata/testimg.jpeg", "./testdata/testimg.jpeg", "testdata/testimg.jpeg"},
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	// When
+	_, err := reddit.PostGallery(context.Background(), req)
+
+	// Then
+	var galleryErr *redmed.GalleryError
+	if !errors.As(err, &galleryErr) {
+		t.Fatalf("want *GalleryError, got %v", err)
+	}
+
+	if len(galleryErr.Rejected) != 1 || galleryErr.Rejected[0] != 1 {
+		t.Fatalf("want item 1 rejected, got %v", galleryErr.Rejected)
+	}
+
+	if !strings.Contains(err.Error(), "item 1 (./testdata/testimg.jpeg") {
+		t.Errorf("want error naming item 1, got %v", err)
+	}
+
+	var apiErr *redmed.APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) != 1 || apiErr.Errors[0].Code != "INVALID_MEDIA" {
+		t.Errorf("want the *APIError wrapped, got %v", err)
+	}
+}
+
+func TestPostVideoGIFLinkFilename(t *testing.T) {
+	// link server. serves the gif to be downloaded
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/test.gif")
+	}))
+	defer linkSvr.Close()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"FromLink", "", "cat.gif"},
+		{"Filename", "funny", "funny.gif"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			mock := redmedtest.NewMockReddit()
+			defer mock.Close()
+
+			reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+			req := redmed.PostVideoRequest{
+				Filename:  tc.filename,
+				VideoPath: linkSvr.URL + "/cat.gif",
+				Subreddit: "subreddit",
+				Title:     "gif test",
+			}
+
+			// When
+			_, err := reddit.PostVideo(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			uploads := mock.Uploads()
+			if len(uploads) != 2 || uploads[0].FileName != tc.want {
+				t.Errorf("want the gif uploaded as %s, got %+v", tc.want, uploads)
+			}
+		})
+	}
+}
+
+func TestUploadMediaValidator(t *testing.T) {
+	// mock reddit. nothing rejected by the validator should be leased
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	mock.Handle("/api/media/asset.json", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("want nothing leased")
+	})
+
+	// Given
+	errTooSmall := errors.New("resolution below 720p")
+	var gotPath, gotMimeType string
+	options := append(mock.Options(), redmed.WithMediaValidator(func(path, mimeType string) error {
+		gotPath, gotMimeType = path, mimeType
+		return errTooSmall
+	}))
+	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", options...)
+
+	// When
+	_, err := reddit.UploadMedia(context.Background(), "testdata/testimg.jpeg")
+
+	// Then
+	if !errors.Is(err, errTooSmall) {
+		t.Fatalf("want the validator's error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "testdata/testimg.jpeg") {
+		t.Errorf("want the path in the error, got %v", err)
+	}
+
+	if gotPath != "testdata/testimg.jpeg" || gotMimeType != "image/jpeg" {
+		t.Errorf("want testdata/testimg.jpeg as image/jpeg validated, got %s as %s", gotPath, gotMimeType)
+	}
+}
+
+func TestPostImageConcurrent(t *testing.T) {
+	// mock reddit. counts the tokens fetched
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	var mu sync.Mutex
+	fetches := 0
+	mock.Handle("/api/v1/access_token", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	})
+
+	// Given
+	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+	// When
+	errs := make([]error, 8)
+	var wg sync.WaitGroup
+	for i := range errs {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = reddit.PostImage(context.Background(), redmed.PostImageRequest{
+				Path:      "testdata/testimg.jpeg",
+				Subreddit: "subreddit",
+				Title:     fmt.Sprintf("concurrent test %d", i),
+			})
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("post %d: %v", i, err)
+		}
+	}
+
+	if fetches != 1 {
+		t.Errorf("want the concurrent posts to share 1 token fetch, got %d", fetches)
+	}
+}
+
+func TestPostImageAsGallery(t *testing.T) {
+	// Given
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+	req := redmed.PostImageRequest{
+		AsGallery:   true,
+		Caption:     "a caption",
+		OutboundURL: "https://example.com",
+		Path:        "testdata/testimg.jpeg",
+		Subreddit:   "subreddit",
+		Title:       "image test",
+	}
+
+	// When
+	result, err := reddit.PostImage(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if result.Name != "t3_mock1" {
+		t.Errorf("want t3_mock1, got %s", result.Name)
+	}
+
+	submissions := mock.Submissions()
+	if len(submissions) != 1 || submissions[0].Endpoint != "/api/submit_gallery_post.json" {
+		t.Fatalf("want a gallery submission, got %+v", submissions)
+	}
+
+	want := map[string]interface{}{"caption": "a caption", "outbound_url": "https://example.com", "media_id": "asset1"}
+	items, _ := submissions[0].JSON["items"].([]interface{})
+	if len(items) != 1 || fmt.Sprint(items[0]) != fmt.Sprint(want) {
+		t.Errorf("want a gallery of %v, got %v", want, items)
+	}
+}
+
+func TestWithPostMetadata(t *testing.T) {
+	// mock reddit. counts lookups of the post it creates
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	lookups := 0
+	mock.Handle("/api/info", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		w.Write([]byte(`{"data": {"children": [{"data": {"name": "t3_mock1", "author": "username", "created_utc": 1661900000.0}}]}}`))
+	})
+
+	req := redmed.PostImageRequest{
+		Path:      "testdata/testimg.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	tests := []struct {
+		name        string
+		fetch       bool
+		wantAuthor  string
+		wantCreated time.Time
+		wantLookups int
+	}{
+		{"Fetch", true, "username", time.Unix(1661900000, 0).UTC(), 1},
+		{"NoFetch", false, "", time.Time{}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			lookups = 0
+			options := append(mock.Options(), redmed.WithPostMetadata(tc.fetch))
+			reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", options...)
+
+			// When
+			result, err := reddit.PostImage(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if result.Author != tc.wantAuthor || !result.CreatedUTC.Equal(tc.wantCreated) {
+				t.Errorf("want %s at %s, got %s at %s", tc.wantAuthor, tc.wantCreated, result.Author, result.CreatedUTC)
+			}
+
+			if lookups != tc.wantLookups {
+				t.Errorf("want %d lookups, got %d", tc.wantLookups, lookups)
+			}
+		})
+	}
+}
+
+func TestWithVerifyPost(t *testing.T) {
+	// mock reddit. automod removes the post as soon as it's created
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	var submitted time.Time
+	mock.Handle("/api/submit", func(w http.ResponseWriter, r *http.Request) {
+		submitted = time.Now()
+		w.Write([]byte(`{"json": {"errors": [], "data": {"name": "t3_x1qxro"}}}`))
+	})
+	mock.Handle("/api/info", func(w http.ResponseWriter, r *http.Request) {
+		if elapsed := time.Since(submitted); elapsed < 50*time.Millisecond {
+			t.Errorf("want the check delayed, looked up after %s", elapsed)
+		}
+		w.Write([]byte(`{"data": {"children": [{"data": {"name": "t3_x1qxro", "removed_by_category": "automod_filtered"}}]}}`))
+	})
+
+	// Given
+	options := append(mock.Options(), redmed.WithVerifyPost(50*time.Millisecond))
+	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", options...)
+
+	req := redmed.PostImageRequest{
+		Path:      "testdata/testimg.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	// When
+	result, err := reddit.PostImage(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if !result.RemovalChecked || !result.Removed {
+		t.Errorf("want the post checked and found removed, got %+v", result)
+	}
+}
+
+func TestCreateCollection(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// mock reddit. creates the collection
+		mock := redmedtest.NewMockReddit()
+		defer mock.Close()
+
+		var form map[string][]string
+		mock.Handle("/api/v1/collections/create_collection", func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			form = r.PostForm
+			w.Write([]byte(`{"collection_id": "2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44", "title": "best of"}`))
+		})
+
+		// Given
+		reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+		// When
+		id, err := reddit.CreateCollection(context.Background(), "t5_2qh1i", "best of", "the best posts", redmed.CollectionLayoutGallery)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if id != "2b8e5c1a-6a55-4e5b-9a6e-3e2f0f8f9d44" {
+			t.Errorf("want the new collection id, got %s", id)
+		}
+
+		want := map[string]string{
+			"sr_fullname":    "t5_2qh1i",
+			"title":          "best of",
+			"description":    "the best posts",
+			"display_layout": "GALLERY",
+		}
+		for k, v := range want {
+			if got := form[k]; len(got) != 1 || got[0] != v {
+				t.Errorf("want %s=%s, got %v", k, v, got)
+			}
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			name              string
+			subredditFullname string
+			title             string
+			layout            redmed.CollectionLayout
+		}{
+			{"SubredditFullname", "subreddit", "best of", redmed.CollectionLayoutTimeline},
+			{"Title", "t5_2qh1i", "", redmed.CollectionLayoutTimeline},
+			{"Layout", "t5_2qh1i", "best of", "grid"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				reddit := redmed.New("userAgent", "clientID", "secret", "username", "password")
+
+				_, err := reddit.CreateCollection(context.Background(), tc.subredditFullname, tc.title, "", tc.layout)
+				if err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+	})
+}
+
+func TestWaitForPost(t *testing.T) {
+	// mock reddit. its websocket reports the post named by the url's path
+	mock := redmedtest.NewMockReddit()
+	defer mock.Close()
+
+	tests := []struct {
+		name         string
+		websocketURL string
+		want         string
+		wantErr      bool
+	}{
+		{"Created", "wss" + strings.TrimPrefix(mock.Websocket.URL, "https") + "/x1qxro", "t3_x1qxro", false},
+		{"NoURL", "", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+			// When
+			name, err := reddit.WaitForPost(context.Background(), tc.websocketURL)
+
+			// Then
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error %t, got %v", tc.wantErr, err)
+			}
+
+			if name != tc.want {
+				t.Errorf("want %q, got %q", tc.want, name)
+			}
+		})
+	}
+}
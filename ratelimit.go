@@ -0,0 +1,91 @@
+package redmed
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// retryAfterPattern finds the wait in a RATELIMIT message, such as "Take a break
+// for 9 minutes before trying again."
+var retryAfterPattern = regexp.MustCompile(`(\d+) (millisecond|second|minute|hour)s?\b`)
+
+// RateLimitInfo is reddit's rate limit budget as of the last response that
+// reported it.
+type RateLimitInfo struct {
+	// Remaining is how many requests are left in the current period.
+	Remaining float64
+	// Used is how many requests have been made in the current period.
+	Used float64
+	// Reset is when the current period ends.
+	Reset time.Time
+}
+
+// parseRateLimit reads the X-Ratelimit headers reddit sends on api responses.
+// The reset header is in seconds from now.
+func parseRateLimit(header http.Header, now time.Time) (RateLimitInfo, bool) {
+	remaining, err := strconv.ParseFloat(header.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return RateLimitInfo{}, false
+	}
+
+	used, err := strconv.ParseFloat(header.Get("X-Ratelimit-Used"), 64)
+	if err != nil {
+		return RateLimitInfo{}, false
+	}
+
+	reset, err := strconv.ParseFloat(header.Get("X-Ratelimit-Reset"), 64)
+	if err != nil {
+		return RateLimitInfo{}, false
+	}
+
+	return RateLimitInfo{
+		Remaining: remaining,
+		Used:      used,
+		Reset:     now.Add(time.Duration(reset * float64(time.Second))),
+	}, true
+}
+
+func (c *reddit) recordRateLimit(header http.Header) {
+	info, ok := parseRateLimit(header, time.Now())
+	if !ok {
+		return
+	}
+
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+	c.rateLimit = &info
+}
+
+func (c *reddit) lastRateLimit() (RateLimitInfo, bool) {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+
+	if c.rateLimit == nil {
+		return RateLimitInfo{}, false
+	}
+	return *c.rateLimit, true
+}
+
+// parseRetryAfter returns the wait a RATELIMIT message asks for, or 0 if it names
+// none.
+func parseRetryAfter(message string) time.Duration {
+	match := retryAfterPattern.FindStringSubmatch(message)
+	if match == nil {
+		return 0
+	}
+
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+
+	units := map[string]time.Duration{
+		"millisecond": time.Millisecond,
+		"second":      time.Second,
+		"minute":      time.Minute,
+		"hour":        time.Hour,
+	}
+	return time.Duration(n) * units[match[2]]
+}
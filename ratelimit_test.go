@@ -0,0 +1,128 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastRateLimit(t *testing.T) {
+	// reddit server. reports its rate limit budget
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Remaining", "598.0")
+		w.Header().Set("X-Ratelimit-Used", "2")
+		w.Header().Set("X-Ratelimit-Reset", "120")
+		w.Write([]byte(`{"access_token": "token"}`))
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoint
+	originalTokenURL := tokenURL
+	defer func() {
+		tokenURL = originalTokenURL
+	}()
+
+	tokenURL = redditSvr.URL + "/api/v1/access_token"
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	c := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient)).(*client)
+
+	_, ok := c.LastRateLimit()
+	if ok {
+		t.Fatal("want no rate limit before any request")
+	}
+
+	// When
+	start := time.Now()
+	err := c.reddit.SetToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	got, ok := c.LastRateLimit()
+	if !ok {
+		t.Fatal("want rate limit")
+	}
+
+	if got.Remaining != 598 || got.Used != 2 {
+		t.Errorf("want 598 remaining and 2 used, got %+v", got)
+	}
+
+	if got.Reset.Before(start.Add(120*time.Second)) || got.Reset.After(time.Now().Add(120*time.Second)) {
+		t.Errorf("want reset in 120s, got %s", got.Reset)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		message string
+		want    time.Duration
+	}{
+		{"Looks like you've been doing that a lot. Take a break for 9 minutes before trying again.", 9 * time.Minute},
+		{"you are doing that too much. try again in 1 minute.", time.Minute},
+		{"Take a break for 30 seconds before trying again.", 30 * time.Second},
+		{"you are doing that too much", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.message, func(t *testing.T) {
+			if got := parseRetryAfter(tc.message); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSubmitRateLimited(t *testing.T) {
+	// reddit server. accepts the submit with a 200 but rate limits it in the body
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"json": {"errors": [["RATELIMIT", "Take a break for 9 minutes before trying again.", "ratelimit"]]}}`))
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL := baseURL
+	defer func() {
+		baseURL = originalBaseURL
+	}()
+
+	baseURL = redditSvr.URL
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setHTTPClient(&http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	})
+
+	// When
+	_, _, err := c.SubmitPost(context.Background(), "", strings.NewReader("kind=image"))
+
+	// Then
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("want *RateLimitError, got %v", err)
+	}
+
+	if rateLimitErr.RetryAfter != 9*time.Minute {
+		t.Errorf("want retry after 9m, got %s", rateLimitErr.RetryAfter)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.hasCode("RATELIMIT") {
+		t.Errorf("want the *APIError wrapped, got %v", err)
+	}
+}
@@ -1,22 +1,51 @@
 package redmed
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"mime"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// reddit accepts videos up to 1GB, the largest media it takes
+const defaultMaxDownloadSize int64 = 1 << 30
+
+// reddit's json and the action server's xml responses are far smaller than this
+const defaultMaxResponseSize int64 = 8 << 20
+
+// long enough for a slow connection, short enough that a hung websocket endpoint
+// doesn't stall a post that was already submitted
+const defaultWebsocketDialTimeout = 30 * time.Second
+
+// a token fetch is retried through a brief outage of the token endpoint, about
+// 3.5 seconds, before a post gives up on it
+const (
+	defaultTokenRetries      = 3
+	defaultTokenRetryBackoff = 500 * time.Millisecond
+)
+
+// far more progress and keepalive messages than reddit sends before an outcome
+const defaultWebsocketMaxMessages = 100
+
 var (
 	tokenURL = "https://www.reddit.com/api/v1/access_token"
 	baseURL  = "https://oauth.reddit.com"
@@ -32,25 +61,105 @@ var (
 )
 
 type reddit struct {
-	clientID    string
-	secret      string
-	username    string
-	password    string
-	userAgent   string
-	client      *http.Client
-	dialer      *websocket.Dialer
-	accessToken string
+	clientID        string
+	secret          string
+	username        string
+	password        string
+	credentials     func(ctx context.Context) (username, password string, err error)
+	userAgent       string
+	userAgentErr    error
+	client          *http.Client
+	dialer          *websocket.Dialer
+	proxy           *url.URL
+	proxyErr        error
+	insecure        bool
+	mediaValidator  func(path, mimeType string) error
+	insecureErr     error
+	trace           func(RequestTiming)
+	headers         http.Header
+	downloadTimeout time.Duration
+	uploadTimeout   time.Duration
+	wsDialTimeout   time.Duration
+	wsMaxMessages   int
+	maxDownloadSize int64
+	maxResponseSize int64
+	checkRedirect   func(*http.Request, []*http.Request) error
+	dryRun          bool
+	retries         int
+	retryBackoff    time.Duration
+	tokenRetries    int
+	tokenBackoff    time.Duration
+	asyncSubmit     bool
+	appOnly         bool
+	tempDir         string
+	tempDirErr      error
+	keepDir         string
+	keepDirErr      error
+	fsys            fs.FS
+	deviceID        string
+	downloadUA      string
+
+	baseURL           string
+	tokenURL          string
+	insecureEndpoints bool
+	endpointErr       error
+
+	tokenMu          sync.Mutex
+	fetchMu          sync.Mutex
+	accessToken      string
+	token            token
+	tokenExpiry      time.Time
+	refresherRunning bool
+
+	rateLimitMu sync.Mutex
+	rateLimit   *RateLimitInfo
+
+	aboutMu sync.Mutex
+	about   map[string]subredditAbout
+
+	flairsMu sync.Mutex
+	flairs   map[string][]Flair
 }
 
 func newReddit(userAgent, clientID, secret, username, password string) *reddit {
 	return &reddit{
-		userAgent: userAgent,
-		clientID:  clientID,
-		secret:    secret,
-		username:  username,
-		password:  password,
-		client:    http.DefaultClient,
-		dialer:    websocket.DefaultDialer,
+		userAgent:    userAgent,
+		userAgentErr: validateUserAgent(userAgent),
+		clientID:     clientID,
+		secret:       secret,
+		username:     username,
+		password:     password,
+		client:       newHTTPClient(),
+		dialer:       websocket.DefaultDialer,
+
+		wsDialTimeout:   defaultWebsocketDialTimeout,
+		wsMaxMessages:   defaultWebsocketMaxMessages,
+		tokenRetries:    defaultTokenRetries,
+		tokenBackoff:    defaultTokenRetryBackoff,
+		maxDownloadSize: defaultMaxDownloadSize,
+		maxResponseSize: defaultMaxResponseSize,
+	}
+}
+
+// newHTTPClient returns the client used when WithHTTPClient isn't given. it has
+// its own transport so other libraries tuning http.DefaultClient don't affect
+// redmed. there is no overall timeout since video uploads can take a long time.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          10,
+			MaxIdleConnsPerHost:   5,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 60 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
 	}
 }
 
@@ -62,19 +171,314 @@ func (c *reddit) setWebsocketDialer(dialer *websocket.Dialer) {
 	c.dialer = dialer
 }
 
-type asset struct {
-	ID        string
-	Location  string
+func (c *reddit) setProxy(proxy *url.URL) {
+	c.proxy = proxy
+}
+
+// applyProxy routes the http client and websocket dialer through the proxy, once
+// every option is applied so it holds whichever client and dialer were given.
+// Neither is modified in place since they may be shared.
+func (c *reddit) applyProxy() {
+	if c.proxy == nil {
+		return
+	}
+
+	transport, err := c.cloneTransport()
+	if err != nil {
+		c.proxyErr = fmt.Errorf("a proxy can't be set on a %w", err)
+		return
+	}
+	transport.Proxy = http.ProxyURL(c.proxy)
+
+	client := *c.client
+	client.Transport = transport
+	c.client = &client
+
+	dialer := *c.dialer
+	dialer.Proxy = http.ProxyURL(c.proxy)
+	c.dialer = &dialer
+}
+
+func (c *reddit) setMediaValidator(validator func(path, mimeType string) error) {
+	c.mediaValidator = validator
+}
+
+func (c *reddit) setInsecureSkipVerify(insecure bool) {
+	c.insecure = insecure
+}
+
+// applyInsecureSkipVerify turns off certificate verification for the http client
+// and websocket dialer, like applyProxy, once every option is applied.
+func (c *reddit) applyInsecureSkipVerify() {
+	if !c.insecure {
+		return
+	}
+
+	transport, err := c.cloneTransport()
+	if err != nil {
+		c.insecureErr = fmt.Errorf("tls verification can't be skipped on a %w", err)
+		return
+	}
+	transport.TLSClientConfig = insecureTLSConfig(transport.TLSClientConfig)
+
+	client := *c.client
+	client.Transport = transport
+	c.client = &client
+
+	dialer := *c.dialer
+	dialer.TLSClientConfig = insecureTLSConfig(dialer.TLSClientConfig)
+	c.dialer = &dialer
+}
+
+// insecureTLSConfig returns a copy of config that skips certificate verification.
+func insecureTLSConfig(config *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	config.InsecureSkipVerify = true
+	return config
+}
+
+// cloneTransport returns a copy of the http client's transport, which must be an
+// *http.Transport to be configured.
+func (c *reddit) cloneTransport() (*http.Transport, error) {
+	switch t := c.client.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return t.Clone(), nil
+	default:
+		return nil, fmt.Errorf("%T transport", t)
+	}
+}
+
+func (c *reddit) setHTTPTrace(trace func(RequestTiming)) {
+	c.trace = trace
+}
+
+func (c *reddit) addHeader(key, value string) {
+	if c.headers == nil {
+		c.headers = make(http.Header)
+	}
+	c.headers.Add(key, value)
+}
+
+// setLocale asks for reddit's responses, including its error messages, in lang.
+func (c *reddit) setLocale(lang string) {
+	if c.headers == nil {
+		c.headers = make(http.Header)
+	}
+	c.headers.Set("Accept-Language", lang)
+}
+
+func (c *reddit) setDownloadTimeout(timeout time.Duration) {
+	c.downloadTimeout = timeout
+}
+
+func (c *reddit) setUploadTimeout(timeout time.Duration) {
+	c.uploadTimeout = timeout
+}
+
+func (c *reddit) setWebsocketDialTimeout(timeout time.Duration) {
+	c.wsDialTimeout = timeout
+}
+
+func (c *reddit) setWebsocketMaxMessages(max int) {
+	c.wsMaxMessages = max
+}
+
+// setDownloadRedirectPolicy makes downloads follow at most maxRedirects redirects,
+// and only to the link's own host unless crossHost.
+func (c *reddit) setDownloadRedirectPolicy(maxRedirects int, crossHost bool) {
+	c.checkRedirect = func(r *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		if !crossHost && r.URL.Host != via[0].URL.Host {
+			return fmt.Errorf("redirect from %s to another host, %s, not allowed", via[0].URL.Host, r.URL.Host)
+		}
+		return nil
+	}
+}
+
+func (c *reddit) setMaxDownloadSize(size int64) {
+	c.maxDownloadSize = size
+}
+
+func (c *reddit) setMaxResponseSize(size int64) {
+	c.maxResponseSize = size
+}
+
+func (c *reddit) setDryRun(dryRun bool) {
+	c.dryRun = dryRun
+}
+
+func (c *reddit) setRetry(retries int, backoff time.Duration) {
+	c.retries = retries
+	c.retryBackoff = backoff
+}
+
+func (c *reddit) setTokenRetry(retries int, backoff time.Duration) {
+	c.tokenRetries = retries
+	c.tokenBackoff = backoff
+}
+
+func (c *reddit) setAsyncSubmit(async bool) {
+	c.asyncSubmit = async
+}
+
+func (c *reddit) setApplicationOnly(appOnly bool) {
+	c.appOnly = appOnly
+}
+
+func (c *reddit) setCredentials(provider func(ctx context.Context) (username, password string, err error)) {
+	c.credentials = provider
+}
+
+// user returns the username of the last token fetched, which a credentials
+// provider may have changed since New.
+func (c *reddit) user() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.username
+}
+
+// setTempDir stages downloads in dir, checking up front that files can be created
+// there. The check's error is returned by every download.
+func (c *reddit) setTempDir(dir string) {
+	c.tempDir = dir
+	c.tempDirErr = nil
+
+	file, err := os.CreateTemp(dir, "redmed*")
+	if err != nil {
+		c.tempDirErr = fmt.Errorf("temp dir %s is not writable: %w", dir, err)
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// setKeepDownloads keeps downloaded media in dir once it is uploaded, checking up
+// front that files can be created there. The check's error is returned by every
+// download.
+func (c *reddit) setKeepDownloads(dir string) {
+	c.keepDir = dir
+	c.keepDirErr = nil
+
+	file, err := os.CreateTemp(dir, "redmed*")
+	if err != nil {
+		c.keepDirErr = fmt.Errorf("keep downloads dir %s is not writable: %w", dir, err)
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// keepDownload moves the media downloaded from link to the keep downloads dir,
+// named after the link, and returns its new path.
+func (c *reddit) keepDownload(path, link string) (string, error) {
+	name := filepath.Base(link)
+	if u, err := url.Parse(link); err == nil {
+		name = filepath.Base(u.Path)
+	}
+	kept := filepath.Join(c.keepDir, name)
+
+	err := os.Rename(path, kept)
+	if err == nil {
+		return kept, nil
+	}
+
+	// the temp dir may be on another file system
+	err = copyFile(path, kept)
+	if err != nil {
+		os.Remove(kept)
+		return "", fmt.Errorf("keeping download of %s: %w", link, err)
+	}
+	os.Remove(path)
+	return kept, nil
+}
+
+func (c *reddit) setDownloadUserAgent(userAgent string) {
+	c.downloadUA = userAgent
+}
+
+// downloadUserAgent returns the User-Agent media is downloaded from links with.
+func (c *reddit) downloadUserAgent() string {
+	if c.downloadUA == "" {
+		return c.userAgent
+	}
+	return c.downloadUA
+}
+
+func (c *reddit) setDeviceID(deviceID string) {
+	c.deviceID = deviceID
+}
+
+// isReddit reports whether rawURL is one of reddit's api or token endpoints, not
+// the action server or a media host.
+func (c *reddit) isReddit(rawURL string) bool {
+	return strings.HasPrefix(rawURL, c.apiURL()) || rawURL == c.tokenEndpoint()
+}
+
+func (c *reddit) setFS(fsys fs.FS) {
+	c.fsys = fsys
+}
+
+// files returns the file system local paths are opened from.
+func (c *reddit) files() fs.FS {
+	if c.fsys == nil {
+		return osFS{}
+	}
+	return c.fsys
+}
+
+// osFS opens paths from the real file system as os.Open does, including the
+// absolute and relative paths an fs.FS doesn't accept.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Asset is media uploaded to reddit.
+type Asset struct {
+	// ID is the asset id, which galleries and inline media reference.
+	ID string
+	// Location is the url of the uploaded media, which image and video posts link.
+	Location string
+	// WebSocket is the url reddit reports the success of a post using the asset on.
 	WebSocket string
+	// DownloadPath is where media downloaded from a link was kept, under
+	// WithKeepDownloads.
+	DownloadPath string
 }
 
 type assetLeaseResponse struct {
 	Args struct {
-		Action string `json:"action"`
-		Fields []struct {
-			Name  string `json:"name"`
-			Value string `json:"value"`
-		} `json:"fields"`
+		Action string       `json:"action"`
+		Fields []leaseField `json:"fields"`
 	} `json:"args"`
 	Asset struct {
 		AssedID      string `json:"asset_id"`
@@ -82,31 +486,84 @@ type assetLeaseResponse struct {
 	} `json:"asset"`
 }
 
-func (c *reddit) UploadAsset(ctx context.Context, path string) (asset, error) {
-	assetPath := path
+// leaseField is a form field the action server needs with an upload, such as the
+// upload's key and signed policy.
+type leaseField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UploadAsset uploads the media at path, a local path or link, to reddit. The
+// mime type reddit is told the media has is looked up from the extension of path
+// unless mimeType is given.
+func (c *reddit) UploadAsset(ctx context.Context, path, mimeType string) (Asset, error) {
+	return c.uploadAsset(ctx, path, "", mimeType, nil)
+}
 
-	var err error
-	var didDownload bool
-	if isValidURL(path) {
-		assetPath, err = downloadLink(ctx, c.client, path)
+// uploadAsset is UploadAsset, naming the upload fileName, as uploadName does, and
+// sending header with the download when path is a link.
+func (c *reddit) uploadAsset(ctx context.Context, path, fileName, mimeType string, header http.Header) (Asset, error) {
+	fileName = uploadName(path, fileName)
+	if !isValidURL(path) {
+		return c.uploadFrom(ctx, c.files(), path, fileName, mimeType)
+	}
+
+	assetPath, err := c.downloadLink(ctx, path, header)
+	if err != nil {
+		return Asset{}, fmt.Errorf("downloading %s: %w", path, err)
+	}
+	defer os.Remove(assetPath)
+
+	asset, err := c.uploadFrom(ctx, osFS{}, assetPath, fileName, mimeType)
+	if err != nil {
+		return Asset{}, err
+	}
+
+	if c.keepDir != "" {
+		asset.DownloadPath, err = c.keepDownload(assetPath, path)
 		if err != nil {
-			return asset{}, fmt.Errorf("downloading %s: %w", path, err)
+			return Asset{}, err
 		}
-		didDownload = true
 	}
 
-	if didDownload {
-		defer os.Remove(assetPath)
+	return asset, nil
+}
+
+// uploadTempFile uploads a file redmed wrote to the temp dir, which is on the real
+// file system even under WithFS, as fileName.
+func (c *reddit) uploadTempFile(ctx context.Context, path, fileName, mimeType string) (Asset, error) {
+	return c.uploadFrom(ctx, osFS{}, path, uploadName(path, fileName), mimeType)
+}
+
+// uploadName returns the name media at path is uploaded as: fileName, with the
+// extension of path added when it has none so the mime type can still be looked
+// up from it, or the base of path when fileName is empty.
+func uploadName(path, fileName string) string {
+	if fileName == "" {
+		return filepath.Base(path)
 	}
 
-	fileName := filepath.Base(path)
-	ext := filepath.Ext(fileName)
+	if filepath.Ext(fileName) == "" {
+		return fileName + filepath.Ext(path)
+	}
+	return fileName
+}
 
-	var mimeType string
-	if v, ok := mimeTypes[ext]; ok {
-		mimeType = v
-	} else {
-		return asset{}, fmt.Errorf("%s not supported", ext)
+// uploadFrom uploads the file at path in fsys to reddit as fileName.
+func (c *reddit) uploadFrom(ctx context.Context, fsys fs.FS, path, fileName, mimeType string) (Asset, error) {
+	var err error
+	if mimeType == "" {
+		mimeType, err = mimeTypeOf(fileName)
+		if err != nil {
+			return Asset{}, err
+		}
+	}
+
+	if c.mediaValidator != nil {
+		err = c.mediaValidator(path, mimeType)
+		if err != nil {
+			return Asset{}, fmt.Errorf("validating %s: %w", path, err)
+		}
 	}
 
 	assetForm := url.Values{
@@ -114,130 +571,324 @@ func (c *reddit) UploadAsset(ctx context.Context, path string) (asset, error) {
 		"mimetype": []string{mimeType},
 	}
 
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/media/asset.json", baseURL), strings.NewReader(assetForm.Encode()))
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/media/asset.json", c.apiURL()), strings.NewReader(assetForm.Encode()))
 	if err != nil {
-		return asset{}, err
+		return Asset{}, err
 	}
-	r.Header.Set("Authorization", fmt.Sprintf("bearer %s", c.accessToken))
+	r.Header.Set("Authorization", c.authorization())
 
 	var ar assetLeaseResponse
 	_, err = c.doRequest(r, "", json.Unmarshal, &ar)
 	if err != nil {
-		return asset{}, err
+		return Asset{}, err
+	}
+
+	if ar.Args.Action == "" {
+		return Asset{}, fmt.Errorf("asset lease returned no action URL")
+	}
+
+	if len(ar.Args.Fields) == 0 {
+		return Asset{}, fmt.Errorf("asset lease returned no upload fields")
 	}
 
 	uploadURL, err := url.Parse(fmt.Sprintf("https:%s", ar.Args.Action))
 	if err != nil {
-		return asset{}, err
+		return Asset{}, err
 	}
 
-	var formBuff bytes.Buffer
-	form := multipart.NewWriter(&formBuff)
+	location, err := c.uploadToLease(ctx, uploadURL.String(), ar, fileName, fsys, path)
+	if err != nil {
+		return Asset{}, err
+	}
 
-	for _, field := range ar.Args.Fields {
-		formField, err := form.CreateFormField(field.Name)
-		if err != nil {
-			return asset{}, err
-		}
+	return Asset{
+		ID:        ar.Asset.AssedID,
+		Location:  location,
+		WebSocket: ar.Asset.WebsocketURL,
+	}, nil
+}
 
-		_, err = formField.Write([]byte(field.Value))
-		if err != nil {
-			return asset{}, err
-		}
+// uploadToLease uploads the file at path to the lease's action url and returns the
+// uploaded media's location. Under WithRetry, failed uploads are retried with the
+// multipart body replayed from the file.
+func (c *reddit) uploadToLease(ctx context.Context, uploadURL string, ar assetLeaseResponse, fileName string, fsys fs.FS, path string) (string, error) {
+	uploadCtx := ctx
+	if c.uploadTimeout > 0 {
+		var cancel context.CancelFunc
+		uploadCtx, cancel = context.WithTimeout(ctx, c.uploadTimeout)
+		defer cancel()
 	}
 
-	formFile, err := form.CreateFormFile("file", fileName)
+	var location string
+	err := c.retry(uploadCtx, func() error {
+		var err error
+		location, err = c.uploadFile(uploadCtx, uploadURL, ar, fileName, fsys, path)
+		return err
+	})
+	if err != nil && ctx.Err() == nil && errors.Is(uploadCtx.Err(), context.DeadlineExceeded) {
+		return "", &UploadTimeoutError{Timeout: c.uploadTimeout, Err: err}
+	}
+	return location, err
+}
+
+// ctxReader stops reading from r once ctx is done, so copying a large file can be
+// abandoned part way.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *ctxReader) Read(p []byte) (int, error) {
+	err := cr.ctx.Err()
 	if err != nil {
-		return asset{}, err
+		return 0, err
 	}
+	return cr.r.Read(p)
+}
 
-	mediaFile, err := os.Open(assetPath)
+func (c *reddit) uploadFile(ctx context.Context, uploadURL string, ar assetLeaseResponse, fileName string, fsys fs.FS, path string) (string, error) {
+	mediaFile, err := fsys.Open(path)
 	if err != nil {
-		return asset{}, err
+		return "", err
 	}
 	defer mediaFile.Close()
 
-	_, err = io.Copy(formFile, mediaFile)
+	info, err := mediaFile.Stat()
 	if err != nil {
-		return asset{}, err
+		return "", err
+	}
+
+	// the body is streamed from the file through a pipe, so a large video isn't held
+	// in memory. its length is counted up front from the same form written without
+	// the file, since the action server needs a content length
+	body, bodyWriter := io.Pipe()
+	form := multipart.NewWriter(bodyWriter)
+
+	var counter countWriter
+	countForm := multipart.NewWriter(&counter)
+	err = countForm.SetBoundary(form.Boundary())
+	if err != nil {
+		return "", err
 	}
 
-	err = form.Close()
+	err = writeUploadForm(countForm, ar, fileName, nil)
 	if err != nil {
-		return asset{}, err
+		return "", err
 	}
+	contentType := form.FormDataContentType()
 
-	r, err = http.NewRequestWithContext(ctx, http.MethodPost, uploadURL.String(), &formBuff)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
 	if err != nil {
-		return asset{}, err
+		return "", err
 	}
+	r.ContentLength = counter.n + info.Size()
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		bodyWriter.CloseWithError(writeUploadForm(form, ar, fileName, &ctxReader{ctx: ctx, r: mediaFile}))
+	}()
+
+	// closing the body unblocks the writer when the request ends without reading all
+	// of it, and the file is only closed once the writer is done with it
+	defer func() {
+		body.Close()
+		<-written
+	}()
 
 	type postResponse struct {
 		Location string `xml:"Location"`
 	}
 
+	// some s3 configurations respond 200 or 204 with no body, which must not be
+	// taken for an upload to a blank location
+	respBody, err := c.doRequest(r, contentType, nil, nil)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode() < http.StatusMultipleChoices {
+			return "", fmt.Errorf("action server responded %d without an upload location", statusErr.StatusCode())
+		}
+		return "", err
+	}
+
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return "", fmt.Errorf("action server responded without an upload location")
+	}
+
 	var pr postResponse
-	respBody, err := c.doRequest(r, form.FormDataContentType(), xml.Unmarshal, &pr)
+	err = xml.Unmarshal(respBody, &pr)
 	if err != nil {
-		return asset{}, err
+		return "", fmt.Errorf("unmarshalling action server response %s: %w", respBody, err)
 	}
 
 	if pr.Location == "" {
-		return asset{}, fmt.Errorf("uploading asset to lease: %w", fmt.Errorf(string(respBody)))
+		return "", fmt.Errorf("empty upload location from action server: %s", respBody)
 	}
 
-	return asset{
-		ID:        ar.Asset.AssedID,
-		Location:  pr.Location,
-		WebSocket: ar.Asset.WebsocketURL,
-	}, nil
+	return pr.Location, nil
+}
+
+// writeUploadForm writes the lease's fields and the media read from file to form,
+// then closes it. A nil file writes the form without the media.
+func writeUploadForm(form *multipart.Writer, ar assetLeaseResponse, fileName string, file io.Reader) error {
+	for _, field := range ar.Args.Fields {
+		err := form.WriteField(field.Name, field.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	formFile, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return err
+	}
+
+	if file != nil {
+		_, err = io.Copy(formFile, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return form.Close()
 }
 
-func (c *reddit) SubmitPost(ctx context.Context, websocketURL string, body io.Reader) (string, error) {
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/submit", baseURL), body)
+// countWriter counts the bytes written to it.
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// SubmitPost submits a post through /api/submit and returns its fullname. A media
+// post is only created once reddit processes its media, which is waited for on
+// the websocket reddit responds with, or websocketURL from the asset lease. The
+// websocket's url is returned too; under WithAsyncSubmit it is all that is
+// returned for a media post.
+func (c *reddit) SubmitPost(ctx context.Context, websocketURL string, body io.Reader) (string, string, error) {
+	if c.dryRun {
+		return DryRunName, "", nil
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/submit", c.apiURL()), body)
 	if err != nil {
-		return "", fmt.Errorf("creating http request: %w", err)
+		return "", "", fmt.Errorf("creating http request: %w", err)
 	}
-	r.Header.Set("Authorization", fmt.Sprintf("bearer %s", c.accessToken))
+	r.Header.Set("Authorization", c.authorization())
 
-	_, err = c.doRequest(r, "", nil, nil)
+	respBody, err := c.doRequest(r, "", nil, nil)
 	if err != nil {
-		return "", fmt.Errorf("executing submission request: %w", err)
+		return "", "", fmt.Errorf("executing submission request: %w", err)
+	}
+
+	// with api_type=json a rejected submit is reported in the body, which is
+	// otherwise empty until the websocket reports success
+	var sr submitResponse
+	if len(bytes.TrimSpace(respBody)) > 0 && json.Unmarshal(respBody, &sr) == nil && len(sr.JSON.Errors) > 0 {
+		return "", "", fmt.Errorf("executing submission request: %w", submitError(sr.JSON.Errors, respBody))
+	}
+
+	// posts without media are created by the submit itself
+	if sr.JSON.Data.Name != "" {
+		return sr.JSON.Data.Name, "", nil
 	}
 
-	redirect, err := c.waitForPostSuccess(ctx, websocketURL)
+	if sr.JSON.Data.WebsocketURL != "" {
+		websocketURL = sr.JSON.Data.WebsocketURL
+	}
+
+	if c.asyncSubmit {
+		return "", websocketURL, nil
+	}
+
+	if websocketURL == "" {
+		return "", "", fmt.Errorf("executing submission request: no post or websocket in response: %s", respBody)
+	}
+
+	name, err := c.waitForPost(ctx, websocketURL)
+	return name, websocketURL, err
+}
+
+// waitForPost waits on the websocket at url for reddit to create a submitted post
+// and returns its fullname.
+func (c *reddit) waitForPost(ctx context.Context, url string) (string, error) {
+	redirect, err := c.waitForPostSuccess(ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("waiting for post success: %w", err)
 	}
 
-	split := strings.Split(redirect, "/")
+	// reddit took the submit, so the post may exist even though it wasn't confirmed
+	if redirect == "" {
+		return "", &WebsocketError{Err: fmt.Errorf("submission accepted but no confirmation received")}
+	}
 
-	return fmt.Sprintf("t3_%s", split[len(split)-3]), nil
+	return fullnameFromRedirect(redirect)
 }
 
-type postGalleryResponse struct {
+// fullnameFromRedirect returns the fullname of the post a redirect such as
+// https://www.reddit.com/r/subreddit/comments/x1qxro/title/ links to.
+func fullnameFromRedirect(redirect string) (string, error) {
+	u, err := url.Parse(redirect)
+	if err != nil {
+		return "", fmt.Errorf("parsing post redirect %s: %w", redirect, err)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "comments" && i+1 < len(segments) && segments[i+1] != "" {
+			return fmt.Sprintf("t3_%s", segments[i+1]), nil
+		}
+	}
+	return "", fmt.Errorf("no post id in redirect %s", redirect)
+}
+
+type submitResponse struct {
 	JSON struct {
-		Errors []interface{} `json:"errors"`
+		Errors []ErrorDetail `json:"errors"`
 		Data   struct {
-			URL string `json:"url"`
-			ID  string `json:"id"`
+			URL  string `json:"url"`
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			// WebsocketURL is where reddit reports when a media post is ready.
+			WebsocketURL string `json:"websocket_url"`
 		} `json:"data"`
 	} `json:"json"`
 }
 
 func (c *reddit) SubmitGalleryPost(ctx context.Context, body io.Reader) (string, error) {
-	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/submit_gallery_post.json", baseURL), body)
+	return c.submitJSON(ctx, "/api/submit_gallery_post.json", body)
+}
+
+func (c *reddit) SubmitPollPost(ctx context.Context, body io.Reader) (string, error) {
+	return c.submitJSON(ctx, "/api/submit_poll_post.json", body)
+}
+
+// submitJSON submits a json payload to one of the json submission endpoints and
+// returns the new post's fullname.
+func (c *reddit) submitJSON(ctx context.Context, endpoint string, body io.Reader) (string, error) {
+	if c.dryRun {
+		return DryRunName, nil
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.apiURL(), endpoint), body)
 	if err != nil {
 		return "", fmt.Errorf("creating http request: %w", err)
 	}
-	r.Header.Set("Authorization", fmt.Sprintf("bearer %s", c.accessToken))
+	r.Header.Set("Authorization", c.authorization())
 
-	var pgr postGalleryResponse
+	var pgr submitResponse
 	respBody, err := c.doRequest(r, "application/json", json.Unmarshal, &pgr)
 	if err != nil {
 		return "", fmt.Errorf("executing submission request: %w", err)
 	}
 
+	if len(pgr.JSON.Errors) > 0 {
+		return "", fmt.Errorf("executing submission request: %w", submitError(pgr.JSON.Errors, respBody))
+	}
+
 	if pgr.JSON.Data.ID == "" {
 		return "", fmt.Errorf("executing submission request: %w", fmt.Errorf(string(respBody)))
 	}
@@ -245,18 +896,101 @@ func (c *reddit) SubmitGalleryPost(ctx context.Context, body io.Reader) (string,
 	return pgr.JSON.Data.ID, nil
 }
 
+// PostForm posts form to a reddit api endpoint that only reports success or failure.
+func (c *reddit) PostForm(ctx context.Context, endpoint string, form url.Values) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.apiURL(), endpoint), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating http request: %w", err)
+	}
+	r.Header.Set("Authorization", c.authorization())
+
+	_, err = c.doRequest(r, "", nil, nil)
+	return err
+}
+
+// PostFormJSON posts form to a reddit api endpoint and unmarshals its json response
+// into v.
+func (c *reddit) PostFormJSON(ctx context.Context, endpoint string, form url.Values, v interface{}) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s%s", c.apiURL(), endpoint), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating http request: %w", err)
+	}
+	r.Header.Set("Authorization", c.authorization())
+
+	_, err = c.doRequest(r, "", json.Unmarshal, v)
+	return err
+}
+
+// GetJSON gets a reddit api endpoint and unmarshals its json response into v.
+func (c *reddit) GetJSON(ctx context.Context, endpoint string, query url.Values, v interface{}) error {
+	u := fmt.Sprintf("%s%s", c.apiURL(), endpoint)
+	if len(query) > 0 {
+		u = fmt.Sprintf("%s?%s", u, query.Encode())
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("creating http request: %w", err)
+	}
+	r.Header.Set("Authorization", c.authorization())
+
+	_, err = c.doRequest(r, "", json.Unmarshal, v)
+	return err
+}
+
 type token struct {
 	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn is how many seconds the token is valid for.
+	ExpiresIn int    `json:"expires_in"`
+	Scope     string `json:"scope"`
 }
 
+// SetToken fetches an oauth token, unless the one last fetched is good for at
+// least another tokenRefreshMargin. Concurrent calls wait for a single fetch
+// rather than each fetching their own.
 func (c *reddit) SetToken(ctx context.Context) error {
+	if c.tokenValid(tokenRefreshMargin) {
+		return nil
+	}
+
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+
+	if c.tokenValid(tokenRefreshMargin) {
+		return nil
+	}
+
+	// rejected credentials fail the fetch right away; only an unreachable or
+	// failing token endpoint is retried
+	return retry(ctx, c.tokenRetries, c.tokenBackoff, func() error {
+		return c.fetchToken(ctx)
+	})
+}
+
+func (c *reddit) fetchToken(ctx context.Context) error {
+	username, password := c.user(), c.password
+	if c.credentials != nil && !c.appOnly {
+		var err error
+		username, password, err = c.credentials(ctx)
+		if err != nil {
+			return fmt.Errorf("getting credentials: %w", err)
+		}
+	}
+
 	form := url.Values{
 		"grant_type": []string{"password"},
-		"username":   []string{c.username},
-		"password":   []string{c.password},
+		"username":   []string{username},
+		"password":   []string{password},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if c.appOnly {
+		form = url.Values{
+			"grant_type": []string{"client_credentials"},
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenEndpoint(), strings.NewReader(form.Encode()))
 	if err != nil {
 		return err
 	}
@@ -272,13 +1006,49 @@ func (c *reddit) SetToken(ctx context.Context) error {
 		return fmt.Errorf("no token in response: %w", fmt.Errorf(string(respBody)))
 	}
 
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.username = username
 	c.accessToken = t.AccessToken
+	c.token = t
+	c.tokenExpiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
 	return nil
 }
 
 func (c *reddit) doRequest(r *http.Request, contentType string, unmarshal func([]byte, interface{}) error, v interface{}) ([]byte, error) {
+	if c.userAgentErr != nil {
+		return nil, c.userAgentErr
+	}
+
+	if c.endpointErr != nil {
+		return nil, c.endpointErr
+	}
+
+	for k, vs := range c.headers {
+		switch k {
+		case "Authorization", "Content-Type", "User-Agent":
+			continue
+		}
+
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+
 	r.Header.Set("User-Agent", c.userAgent)
 
+	if c.deviceID != "" && c.isReddit(r.URL.String()) {
+		r.Header.Set("X-Reddit-Device-Id", c.deviceID)
+	}
+
+	// without raw_json reddit escapes &, <, and > in the json it returns, mangling
+	// urls and error messages
+	if strings.HasPrefix(r.URL.String(), c.apiURL()) {
+		q := r.URL.Query()
+		q.Set("raw_json", "1")
+		r.URL.RawQuery = q.Encode()
+	}
+
 	cType := "application/x-www-form-urlencoded"
 	if contentType != "" {
 		cType = contentType
@@ -286,19 +1056,29 @@ func (c *reddit) doRequest(r *http.Request, contentType string, unmarshal func([
 
 	r.Header.Set("Content-Type", cType)
 
-	resp, err := c.client.Do(r)
+	resp, err := c.do(r)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	respBytes, err := io.ReadAll(resp.Body)
+	c.recordRateLimit(resp.Header)
+
+	respBytes, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseSize+1))
 	if err != nil {
 		return nil, err
 	}
 
+	if int64(len(respBytes)) > c.maxResponseSize {
+		return nil, fmt.Errorf("response from %s exceeds the maximum of %d bytes", r.URL.Redacted(), c.maxResponseSize)
+	}
+
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("status code %d: %s", resp.StatusCode, string(respBytes))
+		statusErr := &StatusError{statusCode: resp.StatusCode, body: respBytes}
+		if resp.StatusCode == http.StatusForbidden && strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope") {
+			return nil, &ScopeError{Required: requiredScope(r.URL.Path), Granted: c.scope(), Err: statusErr}
+		}
+		return nil, statusErr
 	}
 
 	if v != nil {
@@ -311,33 +1091,164 @@ func (c *reddit) doRequest(r *http.Request, contentType string, unmarshal func([
 	return respBytes, nil
 }
 
-func downloadLink(ctx context.Context, client *http.Client, link string) (string, error) {
+// do executes r with the http client, reporting its timings when a trace is set.
+func (c *reddit) do(r *http.Request) (*http.Response, error) {
+	return c.doWith(c.client, r)
+}
+
+func (c *reddit) doWith(client *http.Client, r *http.Request) (*http.Response, error) {
+	if c.proxyErr != nil {
+		return nil, c.proxyErr
+	}
+
+	if c.insecureErr != nil {
+		return nil, c.insecureErr
+	}
+
+	if c.trace == nil {
+		return markRedirectError(client.Do(r))
+	}
+
+	tracer := newRequestTracer(r)
+	r = r.WithContext(httptrace.WithClientTrace(r.Context(), tracer.clientTrace()))
+
+	resp, err := client.Do(r)
+	c.trace(tracer.result())
+	return markRedirectError(resp, err)
+}
+
+// redirectError is the error of a request whose redirect the http client's
+// CheckRedirect refused.
+type redirectError struct {
+	err error
+}
+
+func (e *redirectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *redirectError) Unwrap() error {
+	return e.err
+}
+
+// markRedirectError wraps err in a *redirectError when it comes with resp, which
+// the http client only returns with an error when CheckRedirect refused a redirect.
+func markRedirectError(resp *http.Response, err error) (*http.Response, error) {
+	if err != nil && resp != nil {
+		return resp, &redirectError{err: err}
+	}
+	return resp, err
+}
+
+// downloadClient returns the http client downloads are made with, the usual one
+// with the download redirect policy when one is set.
+func (c *reddit) downloadClient() *http.Client {
+	if c.checkRedirect == nil {
+		return c.client
+	}
+
+	client := *c.client
+	client.CheckRedirect = c.checkRedirect
+	return &client
+}
+
+// downloadLink downloads link to a temporary file, sending header with the
+// request.
+func (c *reddit) downloadLink(ctx context.Context, link string, header http.Header) (string, error) {
+	if c.tempDirErr != nil {
+		return "", c.tempDirErr
+	}
+
+	if c.keepDirErr != nil {
+		return "", c.keepDirErr
+	}
+
+	downloadCtx := ctx
+	if c.downloadTimeout > 0 {
+		var cancel context.CancelFunc
+		downloadCtx, cancel = context.WithTimeout(ctx, c.downloadTimeout)
+		defer cancel()
+	}
+
+	path, err := c.download(downloadCtx, link, header)
+	if err != nil && ctx.Err() == nil && errors.Is(downloadCtx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("download timed out after %s: %w", c.downloadTimeout, err)
+	}
+	return path, err
+}
+
+func (c *reddit) download(ctx context.Context, link string, header http.Header) (string, error) {
 	r, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := client.Do(r)
+	// hosts commonly block requests without a user agent
+	r.Header.Set("User-Agent", c.downloadUserAgent())
+
+	// WithHTTPHeader's headers are for reddit, not the media's host
+	for k, vs := range header {
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+
+	resp, err := c.doWith(c.downloadClient(), r)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
+	// where the media came from after any redirects, which is what the status and
+	// media type describe
+	final := resp.Request.URL.String()
+
 	if resp.StatusCode != http.StatusOK {
+		if final != link {
+			return "", fmt.Errorf("expectes status code %d, got %d from %s", http.StatusOK, resp.StatusCode, final)
+		}
 		return "", fmt.Errorf("expectes status code %d, got %d", http.StatusOK, resp.StatusCode)
 	}
 
-	file, err := os.CreateTemp("", fmt.Sprintf("redmed*%s", filepath.Ext(link)))
+	if resp.ContentLength > c.maxDownloadSize {
+		return "", fmt.Errorf("download of %d bytes exceeds the maximum of %d bytes", resp.ContentLength, c.maxDownloadSize)
+	}
+
+	body := bufio.NewReaderSize(resp.Body, sniffLen)
+	sniff, err := body.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", err
+	}
+
+	mediaType := downloadMediaType(resp.Header.Get("Content-Type"), sniff)
+	if !isSupportedMediaType(mediaType) {
+		return "", fmt.Errorf("%s is %s, not a supported media type", final, mediaType)
+	}
+
+	file, err := os.CreateTemp(c.tempDir, fmt.Sprintf("redmed*%s", filepath.Ext(link)))
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	// read one byte past the maximum to tell a body of exactly the maximum from a larger one
+	n, err := io.Copy(file, io.LimitReader(body, c.maxDownloadSize+1))
+
+	// close before any removal, which fails on windows while the file is open
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+
 	if err != nil {
+		os.Remove(file.Name())
 		return "", err
 	}
 
+	if n > c.maxDownloadSize {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("download exceeds the maximum of %d bytes", c.maxDownloadSize)
+	}
+
 	return file.Name(), nil
 }
 
@@ -345,13 +1256,26 @@ type wsResponse struct {
 	Type    string `json:"type"`
 	Payload struct {
 		Redirect string `json:"redirect"`
+		Message  string `json:"message"`
+		Reason   string `json:"reason"`
+		Error    string `json:"error"`
 	} `json:"payload"`
 }
 
+// reason returns the first of the fields reddit explains a failure with.
+func (wr wsResponse) reason() string {
+	for _, v := range []string{wr.Payload.Reason, wr.Payload.Error, wr.Payload.Message} {
+		if v != "" {
+			return v
+		}
+	}
+	return "unknown"
+}
+
 func (c *reddit) waitForPostSuccess(ctx context.Context, url string) (string, error) {
-	ws, _, err := c.dialer.Dial(url, nil)
+	ws, err := c.dialWebsocket(ctx, url)
 	if err != nil {
-		return "", fmt.Errorf("dialing websocket connection: %w", err)
+		return "", err
 	}
 	defer ws.Close()
 
@@ -360,42 +1284,91 @@ func (c *reddit) waitForPostSuccess(ctx context.Context, url string) (string, er
 		err   error
 	}
 
-	msgCh := make(chan msg)
+	// ReadMessage blocks until a message arrives, so the read deadline is what
+	// lets a cancelled ctx end the reader rather than leave it waiting
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		ws.SetReadDeadline(deadline)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	// buffered so the reader can always hand over its one result and exit, even
+	// once nothing is receiving
+	msgCh := make(chan msg, 1)
 	go func(ctx context.Context, msgCh chan msg) {
 		defer close(msgCh)
 
-		for {
+		for read := 0; ; read++ {
 			if ctx.Err() != nil {
 				return
 			}
 
-			// what if message never comes?
+			if c.wsMaxMessages > 0 && read >= c.wsMaxMessages {
+				msgCh <- msg{err: &WebsocketError{Err: fmt.Errorf("no post outcome in %d websocket messages", read)}}
+				return
+			}
+
 			_, message, err := ws.ReadMessage()
 			if err != nil {
-				msgCh <- msg{err: fmt.Errorf("reading websocket message: %w", err)}
+				// ctx's deadline can pass just before ctx is marked done. any other
+				// timeout, such as a dropped keepalive, is the connection's own
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() && (ctx.Err() != nil || hasDeadline && !time.Now().Before(deadline)) {
+					<-ctx.Done()
+				}
+
+				if ctx.Err() != nil {
+					return
+				}
+				msgCh <- msg{err: &WebsocketError{Err: fmt.Errorf("reading websocket message: %w", err)}}
 				return
 			}
 
+			// a message that isn't json, such as a keepalive, is skipped like progress
 			var wr wsResponse
-			err = json.Unmarshal(message, &wr)
-			if err != nil {
-				msgCh <- msg{err: fmt.Errorf("unmarshalling websocket message: %w", err)}
+			if json.Unmarshal(message, &wr) != nil {
+				continue
+			}
+
+			if wr.Type == "failed" {
+				msgCh <- msg{err: &MediaProcessingError{Reason: wr.reason(), Message: string(message)}}
 				return
 			}
 
-			if wr.Type != "success" || wr.Payload.Redirect == "" {
+			// reddit may report progress before the outcome. only success and
+			// failed are terminal
+			if wr.Type != "success" {
+				continue
+			}
+
+			if wr.Payload.Redirect == "" {
 				msgCh <- msg{err: fmt.Errorf("waiting for media upload success: %w", fmt.Errorf(string(message)))}
 				return
 			}
 
 			msgCh <- msg{value: wr.Payload.Redirect, err: nil}
+			return
 		}
 	}(ctx, msgCh)
 
 	select {
 	case <-ctx.Done():
 		return "", ctx.Err()
-	case msg := <-msgCh:
+	case msg, ok := <-msgCh:
+		if !ok {
+			// the reader only gives up without a message once ctx is done
+			return "", ctx.Err()
+		}
+
 		if msg.err != nil {
 			return "", msg.err
 		}
@@ -403,6 +1376,90 @@ func (c *reddit) waitForPostSuccess(ctx context.Context, url string) (string, er
 	}
 }
 
+// dialWebsocket connects to the websocket at url, giving up after the dial timeout
+// so an endpoint that accepts the connection but never upgrades it can't hang
+// the post.
+func (c *reddit) dialWebsocket(ctx context.Context, url string) (*websocket.Conn, error) {
+	dialCtx := ctx
+	if c.wsDialTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, c.wsDialTimeout)
+		defer cancel()
+	}
+
+	header := http.Header{"User-Agent": []string{c.userAgent}}
+	if c.deviceID != "" {
+		header.Set("X-Reddit-Device-Id", c.deviceID)
+	}
+
+	ws, _, err := c.dialer.DialContext(dialCtx, url, header)
+	if err != nil {
+		// the connection's deadline can pass just before dialCtx is marked done
+		var netErr net.Error
+		timedOut := errors.Is(dialCtx.Err(), context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+		if ctx.Err() == nil && c.wsDialTimeout > 0 && timedOut {
+			err = fmt.Errorf("timed out after %s: %w", c.wsDialTimeout, err)
+		}
+		return nil, &WebsocketError{Err: fmt.Errorf("dialing websocket connection: %w", err)}
+	}
+	return ws, nil
+}
+
+// sniffLen is how much of a download http.DetectContentType looks at
+const sniffLen = 512
+
+// downloadMediaType returns the media type of a download from its Content-Type
+// header, falling back to sniffing the first bytes when the header is missing or
+// generic.
+func downloadMediaType(contentType string, sniff []byte) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" || mediaType == "binary/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(sniff))
+	}
+	return mediaType
+}
+
+// isSupportedMediaType reports whether mediaType is one reddit accepts. Binary
+// content that can't be sniffed, such as quicktime video, is given the benefit
+// of the doubt.
+func isSupportedMediaType(mediaType string) bool {
+	if mediaType == "application/octet-stream" {
+		return true
+	}
+
+	for _, v := range mimeTypes {
+		if v == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func mimeTypeOf(path string) (string, error) {
+	ext := filepath.Ext(path)
+	if v, ok := mimeTypes[ext]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("%s not supported", ext)
+}
+
+// validateMimeType checks that an overridden mime type is one of the image or
+// video types reddit could take.
+func validateMimeType(mimeType string) error {
+	if mimeType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil || mediaType != mimeType || (!strings.HasPrefix(mediaType, "image/") && !strings.HasPrefix(mediaType, "video/")) {
+		return fmt.Errorf("mime type %q is not an image/* or video/* type", mimeType)
+	}
+	return nil
+}
+
+// isValidURL reports whether toTest is an http or https link. Anything else is
+// taken for a local path, including a file:// url and a Windows path such as
+// C:/Users/me/image.jpeg, whose drive letter parses as a url scheme.
 func isValidURL(toTest string) bool {
 	_, err := url.ParseRequestURI(toTest)
 	if err != nil {
@@ -410,9 +1467,9 @@ func isValidURL(toTest string) bool {
 	}
 
 	u, err := url.Parse(toTest)
-	if err != nil || u.Scheme == "" || u.Host == "" {
+	if err != nil || u.Host == "" {
 		return false
 	}
 
-	return true
+	return u.Scheme == "http" || u.Scheme == "https"
 }
@@ -0,0 +1,1549 @@
+package redmed
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDownloadLink(t *testing.T) {
+	t.Run("ExceedsMaxSize", func(t *testing.T) {
+		// link server. streams a body larger than it claims to be
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(make([]byte, 1024))
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setMaxDownloadSize(512)
+
+		// When
+		_, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "exceeds the maximum of 512 bytes") {
+			t.Errorf("want max size error, got %v", err)
+		}
+	})
+	t.Run("TimedOut", func(t *testing.T) {
+		// link server. trickles bytes until the client gives up
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(10 * time.Millisecond):
+					w.Write([]byte{0})
+					w.(http.Flusher).Flush()
+				}
+			}
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setDownloadTimeout(100 * time.Millisecond)
+
+		// When
+		_, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "download timed out after 100ms") {
+			t.Errorf("want timeout error, got %v", err)
+		}
+	})
+	t.Run("TempDir", func(t *testing.T) {
+		// link server. where to download an image from
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := os.ReadFile("testdata/testimg.jpeg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b)
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		dir := t.TempDir()
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setTempDir(dir)
+
+		// When
+		path, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if filepath.Dir(path) != dir {
+			t.Errorf("want download in %s, got %s", dir, path)
+		}
+	})
+	t.Run("TempDirNotWritable", func(t *testing.T) {
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setTempDir(filepath.Join(t.TempDir(), "missing"))
+
+		// When
+		_, err := c.downloadLink(context.Background(), "https://host.com/image.jpeg", nil)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "is not writable") {
+			t.Errorf("want not writable error, got %v", err)
+		}
+	})
+	t.Run("UnsupportedContentType", func(t *testing.T) {
+		// link server. an error page behind an image link
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html><body>not found</body></html>"))
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		_, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "is text/html, not a supported media type") {
+			t.Errorf("want media type error, got %v", err)
+		}
+	})
+	t.Run("Headers", func(t *testing.T) {
+		// link server. a cdn that needs a referer and token
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Referer") != "https://source.com" || r.Header.Get("X-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			if r.Header.Get("X-Proxy") != "" {
+				t.Error("want headers for reddit kept from the media host")
+			}
+
+			b, err := os.ReadFile("testdata/testimg.jpeg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b)
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.addHeader("X-Proxy", "proxy")
+
+		// When
+		path, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", http.Header{"Referer": {"https://source.com"}, "X-Token": {"token"}})
+
+		// Then
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(path)
+	})
+	t.Run("RedirectStatus", func(t *testing.T) {
+		// link server. redirects the image to where it is gone
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/image.jpeg" {
+				http.Redirect(w, r, "/cdn/image.jpeg", http.StatusFound)
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		_, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "got 403 from "+linkSvr.URL+"/cdn/image.jpeg") {
+			t.Errorf("want the final status and url, got %v", err)
+		}
+	})
+}
+
+func TestDownloadUserAgent(t *testing.T) {
+	// link server. blocks downloads without a user agent, as many hosts do
+	var gotUA string
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		if gotUA == "" || strings.HasPrefix(gotUA, "Go-http-client") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		b, err := os.ReadFile("testdata/testimg.jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer linkSvr.Close()
+
+	tests := []struct {
+		name       string
+		downloadUA string
+		wantUA     string
+	}{
+		{"ClientUserAgent", "", "userAgent"},
+		{"DownloadUserAgent", "Mozilla/5.0 (compatible; bot/1.0)", "Mozilla/5.0 (compatible; bot/1.0)"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+			c.setDownloadUserAgent(tc.downloadUA)
+
+			// When
+			path, err := c.downloadLink(context.Background(), linkSvr.URL+"/image.jpeg", nil)
+
+			// Then
+			if err != nil {
+				t.Fatalf("want the download let through, got %v", err)
+			}
+			os.Remove(path)
+
+			if gotUA != tc.wantUA {
+				t.Errorf("want user agent %s, got %s", tc.wantUA, gotUA)
+			}
+		})
+	}
+}
+
+func TestDownloadRedirectPolicy(t *testing.T) {
+	// cdn server. where images are redirected to
+	cdnSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := os.ReadFile("testdata/testimg.jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer cdnSvr.Close()
+
+	// link server. /cdn.jpeg redirects to the cdn, /hops/n redirects n more times
+	// before serving the image
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cdn.jpeg" {
+			http.Redirect(w, r, cdnSvr.URL+"/image.jpeg", http.StatusFound)
+			return
+		}
+
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hops/%d", n-1), http.StatusFound)
+			return
+		}
+
+		b, err := os.ReadFile("testdata/testimg.jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer linkSvr.Close()
+
+	tests := []struct {
+		name         string
+		link         string
+		maxRedirects int
+		crossHost    bool
+		wantErr      string
+	}{
+		{name: "WithinMaxRedirects", link: linkSvr.URL + "/hops/2", maxRedirects: 2},
+		{name: "TooManyRedirects", link: linkSvr.URL + "/hops/3", maxRedirects: 2, wantErr: "stopped after 2 redirects"},
+		{name: "NoRedirects", link: linkSvr.URL + "/hops/1", wantErr: "stopped after 0 redirects"},
+		{name: "CrossHost", link: linkSvr.URL + "/cdn.jpeg", maxRedirects: 1, crossHost: true},
+		{name: "CrossHostNotAllowed", link: linkSvr.URL + "/cdn.jpeg", maxRedirects: 1, wantErr: "to another host"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+			c.setDownloadRedirectPolicy(tc.maxRedirects, tc.crossHost)
+
+			// When
+			path, err := c.downloadLink(context.Background(), tc.link, nil)
+
+			// Then
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				os.Remove(path)
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("want error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewRedditHTTPClient(t *testing.T) {
+	// When
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+	// Then
+	if c.client == http.DefaultClient {
+		t.Fatal("want a dedicated client, got http.DefaultClient")
+	}
+
+	transport, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("want *http.Transport, got %T", c.client.Transport)
+	}
+
+	if transport == http.DefaultTransport {
+		t.Error("want a dedicated transport, got http.DefaultTransport")
+	}
+
+	if transport.MaxIdleConnsPerHost == 0 || transport.ResponseHeaderTimeout == 0 {
+		t.Errorf("want idle conn and timeout limits set, got %+v", transport)
+	}
+}
+
+func TestApplyProxy(t *testing.T) {
+	proxy, err := url.Parse("socks5://127.0.0.1:1080")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		// Given
+		httpClient := &http.Client{Transport: &http.Transport{}}
+		dialer := &websocket.Dialer{}
+
+		// When
+		c := New("userAgent", "clientID", "secret", "username", "password",
+			WithProxy(proxy),
+			WithHTTPClient(httpClient),
+			WithWebsocketDialer(dialer),
+		).(*client).reddit
+
+		// Then
+		r, err := http.NewRequest(http.MethodGet, "https://oauth.reddit.com", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := c.client.Transport.(*http.Transport).Proxy(r)
+		if err != nil || got.String() != proxy.String() {
+			t.Errorf("want http proxy %s, got %v", proxy, got)
+		}
+
+		got, err = c.dialer.Proxy(r)
+		if err != nil || got.String() != proxy.String() {
+			t.Errorf("want websocket proxy %s, got %v", proxy, got)
+		}
+
+		if httpClient.Transport.(*http.Transport).Proxy != nil || dialer.Proxy != nil {
+			t.Error("want the given client and dialer left unchanged")
+		}
+	})
+	t.Run("UnsupportedTransport", func(t *testing.T) {
+		// Given
+		httpClient := &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(httpClient),
+			WithProxy(proxy),
+		)
+
+		// When
+		_, err := reddit.GetSubmission(context.Background(), "t3_x1qxro")
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "a proxy can't be set") {
+			t.Errorf("want proxy error, got %v", err)
+		}
+	})
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	// https server. self-signed, serving both plain requests and the websocket
+	svr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws" {
+			return
+		}
+
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}`))
+	}))
+	defer svr.Close()
+
+	wsURL := "wss" + strings.TrimPrefix(svr.URL, "https") + "/ws"
+
+	t.Run("Insecure", func(t *testing.T) {
+		// Given
+		httpClient := &http.Client{Transport: &http.Transport{}}
+		c := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(httpClient),
+			WithInsecureSkipVerify(true),
+		).(*client).reddit
+
+		// When
+		resp, err := c.client.Get(svr.URL)
+		if err != nil {
+			t.Fatalf("want the http client to skip verification, got %v", err)
+		}
+		resp.Body.Close()
+
+		_, err = c.waitForPostSuccess(context.Background(), wsURL)
+
+		// Then
+		if err != nil {
+			t.Errorf("want the websocket dialer to skip verification, got %v", err)
+		}
+
+		if tlsConfig := httpClient.Transport.(*http.Transport).TLSClientConfig; tlsConfig != nil && tlsConfig.InsecureSkipVerify || websocket.DefaultDialer.TLSClientConfig != nil {
+			t.Error("want the given client and default dialer left unchanged")
+		}
+	})
+	t.Run("Secure", func(t *testing.T) {
+		// Given
+		c := New("userAgent", "clientID", "secret", "username", "password").(*client).reddit
+
+		// When
+		_, err := c.client.Get(svr.URL)
+		_, wsErr := c.waitForPostSuccess(context.Background(), wsURL)
+
+		// Then
+		if err == nil || wsErr == nil {
+			t.Errorf("want the self-signed server rejected, got %v and %v", err, wsErr)
+		}
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestSetToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		reddit        *reddit
+		wantGrantType string
+		wantUsername  string
+	}{
+		{
+			name:          "Password",
+			reddit:        New("userAgent", "clientID", "secret", "username", "password").(*client).reddit,
+			wantGrantType: "password",
+			wantUsername:  "username",
+		},
+		{
+			name:          "ApplicationOnly",
+			reddit:        NewApplicationOnly("userAgent", "clientID", "secret").(*client).reddit,
+			wantGrantType: "client_credentials",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// token server. checks the grant
+			tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				err := r.ParseForm()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := r.PostForm.Get("grant_type"); got != tc.wantGrantType {
+					t.Errorf("want grant_type %s, got %s", tc.wantGrantType, got)
+				}
+
+				if got := r.PostForm.Get("username"); got != tc.wantUsername {
+					t.Errorf("want username %q, got %q", tc.wantUsername, got)
+				}
+
+				clientID, secret, ok := r.BasicAuth()
+				if !ok || clientID != "clientID" || secret != "secret" {
+					t.Errorf("want basic auth clientID:secret, got %s:%s", clientID, secret)
+				}
+
+				w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 86400, "scope": "submit read"}`))
+			}))
+			defer tokenSvr.Close()
+
+			// save real endpoint
+			originalTokenURL := tokenURL
+			defer func() {
+				tokenURL = originalTokenURL
+			}()
+
+			tokenURL = tokenSvr.URL
+
+			// When
+			start := time.Now()
+			err := tc.reddit.SetToken(context.Background())
+
+			// Then
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.reddit.accessToken != "token" {
+				t.Errorf("want token, got %s", tc.reddit.accessToken)
+			}
+
+			if tc.reddit.token.Scope != "submit read" {
+				t.Errorf("want scope submit read, got %s", tc.reddit.token.Scope)
+			}
+
+			if expiry := tc.reddit.tokenExpiry; expiry.Before(start.Add(24*time.Hour)) || expiry.After(time.Now().Add(24*time.Hour)) {
+				t.Errorf("want expiry in 24h, got %s", expiry)
+			}
+		})
+	}
+}
+
+func TestUploadToLeaseTimeout(t *testing.T) {
+	// action server. never responds in time
+	actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer actionSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setUploadTimeout(100 * time.Millisecond)
+
+	// When
+	_, err := c.uploadToLease(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+	// Then
+	var timeoutErr *UploadTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("want *UploadTimeoutError, got %v", err)
+	}
+
+	if timeoutErr.Timeout != 100*time.Millisecond {
+		t.Errorf("want timeout 100ms, got %s", timeoutErr.Timeout)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestUploadFileStreamsBody(t *testing.T) {
+	want, err := os.ReadFile("testdata/testimg.jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("WholeFile", func(t *testing.T) {
+		// action server. checks the streamed form against its content length
+		actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength <= int64(len(want)) {
+				t.Errorf("want a content length past the file's %d bytes, got %d", len(want), r.ContentLength)
+			}
+
+			err := r.ParseMultipartForm(32 << 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := r.FormValue("key"); got != "value" {
+				t.Errorf("want lease field key=value, got %q", got)
+			}
+
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			got, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("want the %d byte file, got %d bytes", len(want), len(got))
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		ar := assetLeaseResponse{}
+		ar.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+
+		// When
+		_, err := c.uploadFile(context.Background(), actionSvr.URL, ar, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+		// Then
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("BodyNotRead", func(t *testing.T) {
+		// action server. rejects the upload without reading it
+		actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer actionSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		_, err := c.uploadFile(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+		// Then
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode() != http.StatusForbidden {
+			t.Errorf("want a 403 *StatusError, got %v", err)
+		}
+	})
+}
+
+func TestUploadFileEmptyLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"NoLocation", http.StatusCreated, "<PostResponse><Bucket>reddit-uploaded-media</Bucket></PostResponse>", "empty upload location from action server"},
+		{"NoContent", http.StatusNoContent, "", "action server responded 204 without an upload location"},
+		{"EmptyBody", http.StatusOK, "", "action server responded without an upload location"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// action server. accepts the upload without saying where it went
+			actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer actionSvr.Close()
+
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+			// When
+			_, err := c.uploadFile(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+			// Then
+			if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+				t.Errorf("want %s, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCtxReader(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &ctxReader{ctx: ctx, r: strings.NewReader("media")}
+
+	b := make([]byte, 2)
+	_, err := r.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	cancel()
+	_, err = r.Read(b)
+
+	// Then
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestUploadAssetRemovesDownload(t *testing.T) {
+	t.Run("UploadFailed", func(t *testing.T) {
+		// link server. where to download an image from
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := os.ReadFile("testdata/testimg.jpeg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b)
+		}))
+		defer linkSvr.Close()
+
+		// reddit server. fails the asset lease
+		redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoint
+		originalBaseURL := baseURL
+		defer func() {
+			baseURL = originalBaseURL
+		}()
+
+		baseURL = redditSvr.URL
+
+		// Given
+		dir := t.TempDir()
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setTempDir(dir)
+
+		// When
+		_, err := c.UploadAsset(context.Background(), linkSvr.URL+"/image.jpeg", "")
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		// Then
+		assertEmptyDir(t, dir)
+	})
+	t.Run("DownloadCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// link server. sends part of an image, then the download is cancelled
+		linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := os.ReadFile("testdata/testimg.jpeg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b[:len(b)/2])
+			w.(http.Flusher).Flush()
+
+			cancel()
+			<-r.Context().Done()
+		}))
+		defer linkSvr.Close()
+
+		// Given
+		dir := t.TempDir()
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setTempDir(dir)
+
+		// When
+		_, err := c.UploadAsset(ctx, linkSvr.URL+"/image.jpeg", "")
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		// Then
+		assertEmptyDir(t, dir)
+	})
+}
+
+func TestUploadAssetKeepDownloads(t *testing.T) {
+	// link server. where to download an image from
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := os.ReadFile("testdata/testimg.jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer linkSvr.Close()
+
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	// reddit server. leases the asset to the action server
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"args": {"action": "%s", "fields": [{"name": "key", "value": "value"}]}, "asset": {"asset_id": "123"}}`, strings.TrimPrefix(actionSvr.URL, "https:"))
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoint
+	originalBaseURL := baseURL
+	defer func() {
+		baseURL = originalBaseURL
+	}()
+
+	baseURL = redditSvr.URL
+
+	// Given
+	tempDir, keepDir := t.TempDir(), t.TempDir()
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setTempDir(tempDir)
+	c.setKeepDownloads(keepDir)
+	c.setHTTPClient(actionSvr.Client())
+
+	// When
+	asset, err := c.UploadAsset(context.Background(), linkSvr.URL+"/image.jpeg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	want := filepath.Join(keepDir, "image.jpeg")
+	if asset.DownloadPath != want {
+		t.Errorf("want download kept at %s, got %s", want, asset.DownloadPath)
+	}
+
+	if _, err := os.Stat(want); err != nil {
+		t.Error(err)
+	}
+
+	assertEmptyDir(t, tempDir)
+}
+
+func TestUploadAssetMimeType(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	// reddit server. checks the leased mime type
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := r.PostForm.Get("mimetype"); got != "image/webp" {
+			t.Errorf("want mimetype image/webp, got %s", got)
+		}
+		fmt.Fprintf(w, `{"args": {"action": "%s", "fields": [{"name": "key", "value": "value"}]}, "asset": {"asset_id": "123"}}`, strings.TrimPrefix(actionSvr.URL, "https:"))
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoint
+	originalBaseURL := baseURL
+	defer func() {
+		baseURL = originalBaseURL
+	}()
+
+	baseURL = redditSvr.URL
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setHTTPClient(actionSvr.Client())
+
+	// When
+	_, err := c.UploadAsset(context.Background(), "testdata/testimg.jpeg", "image/webp")
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadAssetFilename(t *testing.T) {
+	// action server. checks the name the file is uploaded as
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if header.Filename != "cat.jpeg" {
+			t.Errorf("want file uploaded as cat.jpeg, got %s", header.Filename)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	// reddit server. checks the leased file name and its mime type
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := r.PostForm.Get("filepath"); got != "cat.jpeg" {
+			t.Errorf("want filepath cat.jpeg, got %s", got)
+		}
+
+		if got := r.PostForm.Get("mimetype"); got != "image/jpeg" {
+			t.Errorf("want mimetype image/jpeg, got %s", got)
+		}
+		fmt.Fprintf(w, `{"args": {"action": "%s", "fields": [{"name": "key", "value": "value"}]}, "asset": {"asset_id": "123"}}`, strings.TrimPrefix(actionSvr.URL, "https:"))
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoint
+	originalBaseURL := baseURL
+	defer func() {
+		baseURL = originalBaseURL
+	}()
+
+	baseURL = redditSvr.URL
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setHTTPClient(actionSvr.Client())
+
+	// When
+	_, err := c.uploadAsset(context.Background(), "testdata/testimg.jpeg", "cat", "", nil)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadName(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		fileName string
+		want     string
+	}{
+		{"Default", "/tmp/redmed123456.jpeg", "", "redmed123456.jpeg"},
+		{"NoExtension", "/tmp/redmed123456.jpeg", "cat", "cat.jpeg"},
+		{"Extension", "/tmp/redmed123456.jpeg", "cat.jpg", "cat.jpg"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := uploadName(tc.path, tc.fileName); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUploadAssetInvalidLease(t *testing.T) {
+	tests := []struct {
+		name    string
+		lease   string
+		wantErr string
+	}{
+		{"NoAction", `{"args": {"fields": [{"name": "key", "value": "value"}]}, "asset": {"asset_id": "123"}}`, "asset lease returned no action URL"},
+		{"NoFields", `{"args": {"action": "//127.0.0.1:1"}, "asset": {"asset_id": "123"}}`, "asset lease returned no upload fields"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// reddit server. returns the lease
+			redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tc.lease))
+			}))
+			defer redditSvr.Close()
+
+			// save real endpoint
+			originalBaseURL := baseURL
+			defer func() {
+				baseURL = originalBaseURL
+			}()
+
+			baseURL = redditSvr.URL
+
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+			// When
+			_, err := c.UploadAsset(context.Background(), "testdata/testimg.jpeg", "")
+
+			// Then
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("want %s, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateMimeType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		wantErr  bool
+	}{
+		{"", false},
+		{"image/webp", false},
+		{"video/webm", false},
+		{"application/pdf", true},
+		{"image/png; charset=utf-8", true},
+		{"webp", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.mimeType, func(t *testing.T) {
+			// When
+			err := validateMimeType(tc.mimeType)
+
+			// Then
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("want no files left in %s, got %d", dir, len(entries))
+	}
+}
+
+func TestWaitForPostSuccess(t *testing.T) {
+	// newWebsocketServer returns the url of a websocket server that sends messages
+	// and then closes the connection.
+	newWebsocketServer := func(t *testing.T, messages ...string) string {
+		wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			for _, m := range messages {
+				err = c.WriteMessage(websocket.TextMessage, []byte(m))
+				if err != nil {
+					return
+				}
+			}
+		}))
+		t.Cleanup(wsSvr.Close)
+
+		return "ws" + strings.TrimPrefix(wsSvr.URL, "http")
+	}
+
+	t.Run("Failed", func(t *testing.T) {
+		// Given
+		wsURL := newWebsocketServer(t, `{"type": "failed", "payload": {"message": "media is corrupt"}}`)
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		_, err := c.waitForPostSuccess(context.Background(), wsURL)
+
+		// Then
+		var mpErr *MediaProcessingError
+		if !errors.As(err, &mpErr) {
+			t.Fatalf("want *MediaProcessingError, got %v", err)
+		}
+
+		if mpErr.Reason != "media is corrupt" {
+			t.Errorf("want reason media is corrupt, got %s", mpErr.Reason)
+		}
+	})
+	t.Run("IntermediateMessages", func(t *testing.T) {
+		// Given
+		wsURL := newWebsocketServer(t,
+			`{"type": "processing", "payload": {"message": "transcoding"}}`,
+			`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}`,
+		)
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		redirect, err := c.waitForPostSuccess(context.Background(), wsURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if redirect != "https://www.reddit.com/r/subreddit/comments/x1qxro/title/" {
+			t.Errorf("want the success redirect, got %s", redirect)
+		}
+	})
+	t.Run("Keepalives", func(t *testing.T) {
+		// websocket server. pings and sends keepalives before the outcome
+		wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			for i := 0; i < 3; i++ {
+				c.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second))
+				c.WriteMessage(websocket.TextMessage, []byte("keepalive"))
+			}
+			c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}`))
+
+			// answers to the pings are only read here
+			c.ReadMessage()
+		}))
+		defer wsSvr.Close()
+
+		wsURL := "ws" + strings.TrimPrefix(wsSvr.URL, "http")
+
+		tests := []struct {
+			name        string
+			maxMessages int
+			wantErr     bool
+		}{
+			{"WithinMax", 4, false},
+			{"PastMax", 3, true},
+			{"Unbounded", 0, false},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				// Given
+				c := newReddit("userAgent", "clientID", "secret", "username", "password")
+				c.setWebsocketMaxMessages(tc.maxMessages)
+
+				// When
+				_, err := c.waitForPostSuccess(context.Background(), wsURL)
+
+				// Then
+				var wsErr *WebsocketError
+				if tc.wantErr != errors.As(err, &wsErr) {
+					t.Errorf("want *WebsocketError %t, got %v", tc.wantErr, err)
+				}
+			})
+		}
+	})
+	t.Run("CustomDialer", func(t *testing.T) {
+		// websocket server. self-signed, so only a dialer that skips verification connects
+		wsSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+
+			c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}`))
+		}))
+		defer wsSvr.Close()
+
+		wsURL := "wss" + strings.TrimPrefix(wsSvr.URL, "https")
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setWebsocketDialer(&websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})
+
+		// When
+		_, err := c.waitForPostSuccess(context.Background(), wsURL)
+
+		// Then
+		if err != nil {
+			t.Fatalf("want the custom dialer used, got %v", err)
+		}
+
+		_, err = newReddit("userAgent", "clientID", "secret", "username", "password").waitForPostSuccess(context.Background(), wsURL)
+		if err == nil {
+			t.Error("want the default dialer to reject the self-signed server")
+		}
+	})
+	t.Run("ReadError", func(t *testing.T) {
+		// Given
+		wsURL := newWebsocketServer(t)
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		_, err := c.waitForPostSuccess(context.Background(), wsURL)
+
+		// Then
+		var wsErr *WebsocketError
+		if !errors.As(err, &wsErr) {
+			t.Fatalf("want *WebsocketError, got %v", err)
+		}
+	})
+	t.Run("DialTimeout", func(t *testing.T) {
+		// websocket server. accepts connections but never answers the upgrade
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					<-done
+					conn.Close()
+				}()
+			}
+		}()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setWebsocketDialer(&websocket.Dialer{})
+		c.setWebsocketDialTimeout(100 * time.Millisecond)
+
+		// When
+		start := time.Now()
+		_, err = c.waitForPostSuccess(context.Background(), "ws://"+l.Addr().String())
+
+		// Then
+		var wsErr *WebsocketError
+		if !errors.As(err, &wsErr) {
+			t.Fatalf("want *WebsocketError, got %v", err)
+		}
+
+		if !strings.Contains(err.Error(), "timed out after 100ms") {
+			t.Errorf("want the dial timeout in the error, got %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("want the dial to give up promptly, took %s", elapsed)
+		}
+	})
+	t.Run("Cancel", func(t *testing.T) {
+		// websocket server. upgrades the connection and never sends a message
+		release := make(chan struct{})
+		wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			<-release
+		}))
+
+		// Given
+		before := runtime.NumGoroutine()
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		// When
+		_, err := c.waitForPostSuccess(ctx, "ws"+strings.TrimPrefix(wsSvr.URL, "http"))
+		close(release)
+		wsSvr.Close()
+
+		// Then
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want context.DeadlineExceeded, got %v", err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if n := runtime.NumGoroutine(); n > before {
+			t.Errorf("want the websocket reader stopped, %d goroutines left over %d", n, before)
+		}
+	})
+	t.Run("ConnectionTimeout", func(t *testing.T) {
+		// websocket server. upgrades the connection and never sends a message
+		release := make(chan struct{})
+		wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer c.Close()
+			<-release
+		}))
+		defer wsSvr.Close()
+		defer close(release)
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+		// the connection times out on its own after the upgrade, as when a tcp
+		// keepalive goes unanswered
+		c.setWebsocketDialer(&websocket.Dialer{
+			NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				return &timeoutConn{Conn: conn}, nil
+			},
+		})
+
+		done := make(chan error, 1)
+
+		// When
+		go func() {
+			_, err := c.waitForPostSuccess(context.Background(), "ws"+strings.TrimPrefix(wsSvr.URL, "http"))
+			done <- err
+		}()
+
+		// Then
+		select {
+		case err := <-done:
+			var wsErr *WebsocketError
+			if !errors.As(err, &wsErr) {
+				t.Errorf("want *WebsocketError, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("want the connection's timeout returned, still waiting")
+		}
+	})
+}
+
+// timeoutConn passes through its first read, the websocket upgrade, and times out
+// on every read after it.
+type timeoutConn struct {
+	net.Conn
+	reads int
+}
+
+func (c *timeoutConn) Read(p []byte) (int, error) {
+	c.reads++
+	if c.reads > 1 {
+		return 0, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ETIMEDOUT)}
+	}
+	return c.Conn.Read(p)
+}
+
+func TestDoRequestLocale(t *testing.T) {
+	// reddit server. checks the language asked for
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Values("Accept-Language"); len(got) != 1 || got[0] != "en-US" {
+			t.Errorf("want Accept-Language en-US, got %v", got)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setLocale("de-DE")
+	c.setLocale("en-US")
+
+	r, err := http.NewRequest(http.MethodGet, redditSvr.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	_, err = c.doRequest(r, "", nil, nil)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoRequestMaxResponseSize(t *testing.T) {
+	// reddit server. responds with more than the client accepts
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1024))
+	}))
+	defer redditSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setMaxResponseSize(512)
+
+	r, err := http.NewRequest(http.MethodGet, redditSvr.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	_, err = c.doRequest(r, "", nil, nil)
+
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum of 512 bytes") {
+		t.Errorf("want max size error, got %v", err)
+	}
+}
+
+func TestErrorRawResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+	}{
+		{name: "APIError", status: http.StatusOK, body: `{"json": {"errors": [["SUBREDDIT_NOEXIST", "that subreddit doesn't exist", "sr"]]}}`, wantStatus: http.StatusOK},
+		{name: "StatusError", status: http.StatusInternalServerError, body: `{"message": "Internal Server Error", "error": 500}`, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// reddit server. rejects the submit
+			redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer redditSvr.Close()
+
+			// save real endpoint
+			originalBaseURL := baseURL
+			defer func() {
+				baseURL = originalBaseURL
+			}()
+			baseURL = redditSvr.URL
+
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+			// When
+			_, _, err := c.SubmitPost(context.Background(), "", strings.NewReader("kind=self"))
+
+			// Then
+			var respErr interface {
+				StatusCode() int
+				RawResponse() []byte
+			}
+			if !errors.As(err, &respErr) {
+				t.Fatalf("want an error with the response, got %v", err)
+			}
+
+			if respErr.StatusCode() != tc.wantStatus {
+				t.Errorf("want status code %d, got %d", tc.wantStatus, respErr.StatusCode())
+			}
+
+			if string(respErr.RawResponse()) != tc.body {
+				t.Errorf("want raw response %s, got %s", tc.body, respErr.RawResponse())
+			}
+		})
+	}
+}
+
+func TestFullnameFromRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect string
+		want     string
+		wantErr  bool
+	}{
+		{"TrailingSlash", "https://www.reddit.com/r/subreddit/comments/x1qxro/title/", "t3_x1qxro", false},
+		{"NoTrailingSlash", "https://www.reddit.com/r/subreddit/comments/x1qxro/title", "t3_x1qxro", false},
+		{"NoTitle", "https://www.reddit.com/r/subreddit/comments/x1qxro", "t3_x1qxro", false},
+		{"Query", "https://www.reddit.com/r/subreddit/comments/x1qxro/title/?utm_source=share", "t3_x1qxro", false},
+		{"Relative", "/r/subreddit/comments/x1qxro/title/", "t3_x1qxro", false},
+		{"NoComments", "https://www.reddit.com/r/subreddit/", "", true},
+		{"NoID", "https://www.reddit.com/r/subreddit/comments/", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When
+			got, err := fullnameFromRedirect(tc.redirect)
+
+			// Then
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDoRequestHeaders(t *testing.T) {
+	// reddit server. checks the request headers
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Values("X-Proxy-Token"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("want X-Proxy-Token a and b, got %v", got)
+		}
+
+		if got := r.Header.Get("Authorization"); got != "bearer token" {
+			t.Errorf("want Authorization bearer token, got %s", got)
+		}
+
+		if got := r.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+			t.Errorf("want form Content-Type, got %s", got)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.addHeader("X-Proxy-Token", "a")
+	c.addHeader("X-Proxy-Token", "b")
+	c.addHeader("Authorization", "bearer proxy")
+	c.addHeader("Content-Type", "text/plain")
+
+	r, err := http.NewRequest(http.MethodPost, redditSvr.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Authorization", "bearer token")
+
+	// When
+	_, err = c.doRequest(r, "", nil, nil)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsValidURL(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"HTTP", "http://i.imgur.com/image.jpeg", true},
+		{"HTTPS", "https://i.imgur.com/image.jpeg", true},
+		{"UpperCaseScheme", "HTTPS://i.imgur.com/image.jpeg", true},
+		{"File", "file:///home/user/image.jpeg", false},
+		{"FTP", "ftp://example.com/image.jpeg", false},
+		{"JavaScript", "javascript:alert(1)", false},
+		{"WindowsPath", `C:\Users\me\image.jpeg`, false},
+		{"WindowsForwardSlashes", "C:/Users/me/image.jpeg", false},
+		{"WindowsLowerCaseDrive", "c:/Users/me/image.jpeg", false},
+		{"UNCPath", `\\server\share\image.jpeg`, false},
+		{"UNCForwardSlashes", "//server/share/image.jpeg", false},
+		{"LocalPath", "/path/to/image.jpeg", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isValidURL(tc.path); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
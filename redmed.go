@@ -4,22 +4,144 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"golang.org/x/sync/errgroup"
 )
 
+// Client posts media to reddit. It's safe for concurrent use, so one Client can
+// serve many goroutines posting at once, sharing its oauth token.
 type Client interface {
-	PostImage(ctx context.Context, req PostImageRequest) (string, error)
-	PostVideo(ctx context.Context, req PostVideoRequest) (string, error)
+	PostImage(ctx context.Context, req PostImageRequest) (PostResult, error)
+	PostVideo(ctx context.Context, req PostVideoRequest) (PostResult, error)
+
+	// PostGallery uploads the items of req at once and submits them as a gallery.
+	// A failed upload doesn't cancel the others: each runs to the end so the error
+	// names every item that failed, though the media of the rest goes unused.
+	// Cancel ctx to stop them all.
 	PostGallery(ctx context.Context, req PostGalleryRequest) (string, error)
+
+	PostPoll(ctx context.Context, req PostPollRequest) (string, error)
+
+	// PostToSubreddits uploads the image of req once and posts it to each of
+	// subreddits, in place of req.Subreddit, checking each as PostImage does. The
+	// results are in the order of subreddits, and a subreddit that failed has an
+	// empty result and is named in the returned error; the others are still
+	// posted. Every result is empty if the upload fails. It can't post a gallery
+	// or a gif too large for an image post.
+	PostToSubreddits(ctx context.Context, req PostImageRequest, subreddits []string) ([]PostResult, error)
+
+	// PostTextWithMedia submits a self post whose markdown shows uploaded images,
+	// gifs, or videos inline, and returns its fullname.
+	PostTextWithMedia(ctx context.Context, req PostTextWithMediaRequest) (string, error)
+
+	// Submit submits a post built from fields, the form /api/submit takes, for
+	// kinds and fields the typed methods don't cover. It returns the fullname of the
+	// post, waiting for reddit to process its media if it has any.
+	Submit(ctx context.Context, fields url.Values) (string, error)
+
+	// WaitForPost waits on the websocket of a post submitted under WithAsyncSubmit,
+	// its PostResult's WebsocketURL, for reddit to create the post and returns its
+	// fullname. It can run apart from the submit, even in another process.
+	WaitForPost(ctx context.Context, websocketURL string) (string, error)
+
+	// UploadMedia uploads the image or video at path, a local path or link, without
+	// submitting a post, so it can be referenced or submitted later.
+	UploadMedia(ctx context.Context, path string) (Asset, error)
+
+	// MarkNSFW, UnmarkNSFW, MarkSpoiler, and UnmarkSpoiler update an existing post
+	// named by its fullname, such as t3_x2dx7f.
+	MarkNSFW(ctx context.Context, fullname string) error
+	UnmarkNSFW(ctx context.Context, fullname string) error
+	MarkSpoiler(ctx context.Context, fullname string) error
+	UnmarkSpoiler(ctx context.Context, fullname string) error
+
+	// Sticky pins (state true) or unpins a post in stickied slot 1 or 2 of its
+	// subreddit, and Distinguish marks a post as yes (moderator), no, admin, or
+	// special. Both need moderator permissions.
+	Sticky(ctx context.Context, fullname string, state bool, slot int) error
+	Distinguish(ctx context.Context, fullname, how string) error
+
+	// SetSuggestedSort sets the comment sort a post's comments are shown in by
+	// default, one of confidence, top, new, controversial, old, random, qa, or
+	// live, or clears it if sort is empty. SetContestMode turns contest mode, which
+	// hides comment scores and shows comments in random order, on or off. Both need
+	// moderator permissions.
+	SetSuggestedSort(ctx context.Context, fullname, sort string) error
+	SetContestMode(ctx context.Context, fullname string, enabled bool) error
+
+	// AddToCollection adds the post named by fullname to the subreddit collection
+	// with the id collectionID, a UUID. It needs moderator permissions.
+	AddToCollection(ctx context.Context, collectionID, fullname string) error
+
+	// CreateCollection creates a collection in the subreddit named by
+	// subredditFullname, such as t5_2qh1i, and returns its id. It needs moderator
+	// permissions.
+	CreateCollection(ctx context.Context, subredditFullname, title, description string, layout CollectionLayout) (string, error)
+
+	// SubmitImageAsset and SubmitVideoAsset submit a post of media already uploaded
+	// with UploadMedia, such as to submit it to more subreddits or retry a submit
+	// without uploading the media again. The paths of req are not used, and a
+	// video's Kind defaults to VideoKindVideo.
+	SubmitImageAsset(ctx context.Context, asset Asset, req PostImageRequest) (string, error)
+	SubmitVideoAsset(ctx context.Context, video, thumbnail Asset, req PostVideoRequest) (string, error)
+
+	// IsRepost reports whether link was already posted to subreddit and, if so, the
+	// permalink of the earlier post. Only link posts of that exact url are found:
+	// an uploaded image or video gets a new url each time, so a repost of the same
+	// media isn't.
+	IsRepost(ctx context.Context, subreddit, link string) (bool, string, error)
+
+	// ListFlairs returns the post flairs subreddit offers, to pick a FlairID from.
+	ListFlairs(ctx context.Context, subreddit string) ([]Flair, error)
+
+	// GetSubmission returns the current state of the post named by fullname, to
+	// confirm a post is live, check its score, or detect its removal.
+	GetSubmission(ctx context.Context, fullname string) (*Submission, error)
+
+	// GetMySubmissions returns up to limit, at most 100, of the user's posts, newest
+	// first, starting after the post named by after, or from the newest if it is
+	// empty. The returned cursor is the after of the next page, empty on the last
+	// one.
+	GetMySubmissions(ctx context.Context, limit int, after string) ([]Submission, string, error)
+
+	// StartTokenRefresher fetches an oauth token and then keeps refreshing it in
+	// the background shortly before it expires, until ctx is done, so posts never
+	// wait on a token. It returns an error if the first token can't be fetched or
+	// a refresher is already running.
+	StartTokenRefresher(ctx context.Context) error
+
+	// TokenExpiry returns when the last oauth token fetched expires, or the zero
+	// time if none has been fetched.
+	TokenExpiry() time.Time
+
+	// TokenInfo returns the scopes, type, and expiry of the oauth token, fetching
+	// one if none is cached, to confirm the app has the scopes posting needs.
+	TokenInfo(ctx context.Context) (TokenInfo, error)
+
+	// LastRateLimit returns the rate limit budget reported by the last reddit
+	// response that had one, for callers pacing their own requests.
+	LastRateLimit() (RateLimitInfo, bool)
+
+	// ValidateMedia runs every local check that applies to posting path, a local path
+	// or link, as kind (image, video, or videogif) and returns a *ValidationError
+	// listing all of the problems found. Video duration and codec are not checked.
+	ValidateMedia(ctx context.Context, path string, kind string) error
 }
 
+// DryRunName is returned in place of a post's fullname when WithDryRun is set.
+const DryRunName = "dryrun"
+
 type Option func(*client)
 
 func WithHTTPClient(httpClient *http.Client) Option {
@@ -34,56 +156,675 @@ func WithWebsocketDialer(dialer *websocket.Dialer) Option {
 	}
 }
 
+// WithProxy sends every request, and the websocket reddit reports post success
+// on, through proxy, an http, https, or socks5 url. It overrides HTTP_PROXY and the
+// proxy of a client given with WithHTTPClient, whose transport must be an
+// *http.Transport.
+func WithProxy(proxy *url.URL) Option {
+	return func(c *client) {
+		c.reddit.setProxy(proxy)
+	}
+}
+
+// WithInsecureSkipVerify skips verifying the certificates of every https server
+// and websocket redmed connects to, for testing against local mocks with
+// self-signed certificates. Never use it against reddit. Like WithProxy, it needs
+// the transport of a client given with WithHTTPClient to be an *http.Transport.
+func WithInsecureSkipVerify(insecure bool) Option {
+	return func(c *client) {
+		c.reddit.setInsecureSkipVerify(insecure)
+	}
+}
+
+// WithBaseURL sends requests to the reddit api at rawURL instead of
+// https://oauth.reddit.com. The websocket reddit reports post success on is used
+// as reddit returns it. rawURL must be https unless WithInsecureEndpoints is given;
+// an invalid one fails every request.
+func WithBaseURL(rawURL string) Option {
+	return func(c *client) {
+		c.reddit.setBaseURL(rawURL)
+	}
+}
+
+// WithTokenURL fetches oauth tokens from rawURL instead of
+// https://www.reddit.com/api/v1/access_token, independently of WithBaseURL. rawURL
+// must be https unless WithInsecureEndpoints is given.
+func WithTokenURL(rawURL string) Option {
+	return func(c *client) {
+		c.reddit.setTokenURL(rawURL)
+	}
+}
+
+// WithInsecureEndpoints allows WithBaseURL and WithTokenURL to be http urls, for
+// local mocks of reddit. Credentials are then sent unencrypted.
+func WithInsecureEndpoints(insecure bool) Option {
+	return func(c *client) {
+		c.reddit.setInsecureEndpoints(insecure)
+	}
+}
+
+// WithHTTPTrace reports the connection phase timings (DNS, connect, TLS handshake,
+// time to first byte) of every outgoing request to fn. This helps tell network
+// issues apart from a slow Reddit.
+func WithHTTPTrace(fn func(RequestTiming)) Option {
+	return func(c *client) {
+		c.reddit.setHTTPTrace(fn)
+	}
+}
+
+// WithDownloadUserAgent sets the User-Agent media is downloaded from links with,
+// for hosts that block the one reddit is sent. It defaults to the client's user
+// agent.
+func WithDownloadUserAgent(userAgent string) Option {
+	return func(c *client) {
+		c.reddit.setDownloadUserAgent(userAgent)
+	}
+}
+
+// WithDeviceID sends deviceID, a stable identifier of the installation such as a
+// UUID, with requests to reddit and its websocket, so reddit sees the same client
+// across restarts.
+func WithDeviceID(deviceID string) Option {
+	return func(c *client) {
+		c.reddit.setDeviceID(deviceID)
+	}
+}
+
+// WithLocale sets the Accept-Language of every request, such as en-US, so that
+// reddit's responses and error messages come back in a predictable language
+// whatever the environment the client runs in.
+func WithLocale(lang string) Option {
+	return func(c *client) {
+		c.reddit.setLocale(lang)
+	}
+}
+
+// WithHTTPHeader adds a header to every request made to reddit, including the
+// oauth token request and the media upload, such as a token for an authenticating
+// proxy. It can be given more than once. The Authorization, Content-Type, and
+// User-Agent headers are always the ones redmed sets.
+func WithHTTPHeader(key, value string) Option {
+	return func(c *client) {
+		c.reddit.addHeader(key, value)
+	}
+}
+
+// WithDownloadTimeout bounds how long downloading media from a link may take.
+func WithDownloadTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.reddit.setDownloadTimeout(timeout)
+	}
+}
+
+// WithUploadTimeout bounds uploading media to reddit's action server, including
+// retries, separately from the context passed to a post. An upload that runs
+// out of time fails with an *UploadTimeoutError.
+func WithUploadTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.reddit.setUploadTimeout(timeout)
+	}
+}
+
+// WithWebsocketDialTimeout bounds connecting to the websocket reddit reports a
+// post's outcome on. It defaults to 30 seconds; zero leaves only the dialer's own
+// HandshakeTimeout and the context passed to a post.
+func WithWebsocketDialTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.reddit.setWebsocketDialTimeout(timeout)
+	}
+}
+
+// WithWebsocketMaxMessages gives up waiting on the websocket reddit reports a
+// post's outcome on after max messages that are neither success nor failure, such
+// as progress or keepalives, with a *WebsocketError. It defaults to 100; zero
+// reads until an outcome or the context passed to the post is done.
+func WithWebsocketMaxMessages(max int) Option {
+	return func(c *client) {
+		c.reddit.setWebsocketMaxMessages(max)
+	}
+}
+
+// WithMaxResponseSize sets the largest response body, in bytes, read from reddit
+// and its action server. It defaults to 8MB, far more than reddit's responses
+// need, so a misbehaving server or proxy can't exhaust memory.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *client) {
+		c.reddit.setMaxResponseSize(size)
+	}
+}
+
+// WithKeepDownloads keeps media downloaded from links in dir, named after the
+// link, once it is uploaded, instead of deleting it. The kept paths are returned
+// in PostResult.DownloadPaths and Asset.DownloadPath.
+func WithKeepDownloads(dir string) Option {
+	return func(c *client) {
+		c.reddit.setKeepDownloads(dir)
+	}
+}
+
+// WithMaxDownloadSize sets the largest body, in bytes, accepted when downloading
+// media from a link. It defaults to 1GB, the largest video Reddit accepts.
+func WithMaxDownloadSize(size int64) Option {
+	return func(c *client) {
+		c.reddit.setMaxDownloadSize(size)
+	}
+}
+
+// WithDownloadRedirectPolicy makes downloading media from a link follow at most
+// maxRedirects redirects, none if it is zero, and only to the link's own host
+// unless crossHost. Without it, downloads follow redirects as the http client
+// does.
+func WithDownloadRedirectPolicy(maxRedirects int, crossHost bool) Option {
+	return func(c *client) {
+		c.reddit.setDownloadRedirectPolicy(maxRedirects, crossHost)
+	}
+}
+
+// WithFS opens the local paths of media from fsys, such as an embed.FS, instead of
+// the real file system. Paths must then be valid fs.FS paths, like
+// media/image.jpeg. Media downloaded from links is still written to the temp dir.
+func WithFS(fsys fs.FS) Option {
+	return func(c *client) {
+		c.reddit.setFS(fsys)
+	}
+}
+
+// WithUserPassword fetches the username and password from provider each time a
+// token is fetched, instead of using the ones given to New, so a password rotated
+// by a secret manager takes effect without a new Client. It isn't used by
+// NewApplicationOnly clients.
+func WithUserPassword(provider func(ctx context.Context) (username, password string, err error)) Option {
+	return func(c *client) {
+		c.reddit.setCredentials(provider)
+	}
+}
+
+// WithMediaValidator calls validator with each file before it's uploaded, such as
+// to check a video's resolution with ffprobe, and fails the upload with its error.
+// path is the file on disk, a temp file for a link, or in the WithFS file system;
+// mimeType is the one reddit will be told. It also sees the thumbnails redmed
+// extracts from gifs.
+func WithMediaValidator(validator func(path, mimeType string) error) Option {
+	return func(c *client) {
+		c.reddit.setMediaValidator(validator)
+	}
+}
+
+// WithRetry retries failed media uploads up to retries times, waiting backoff
+// before the first retry and twice as long before each one after it. Only network
+// errors and server errors are retried; each retry re-sends the whole file.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *client) {
+		c.reddit.setRetry(retries, backoff)
+	}
+}
+
+// WithTokenRetry retries fetching an oauth token up to retries times, waiting
+// backoff before the first retry and twice as long before each one after it. Only
+// network errors and server errors are retried, not rejected credentials. It
+// defaults to 3 retries from 500ms; zero fails on the first error.
+func WithTokenRetry(retries int, backoff time.Duration) Option {
+	return func(c *client) {
+		c.reddit.setTokenRetry(retries, backoff)
+	}
+}
+
+// WithAsyncSubmit returns from image and video posts as soon as reddit accepts the
+// submission, without waiting for it to report the post as created. The returned
+// PostResult then has the AssetID of the media and the WebsocketURL to pass to
+// WaitForPost for the post's fullname, but no Name.
+func WithAsyncSubmit(async bool) Option {
+	return func(c *client) {
+		c.reddit.setAsyncSubmit(async)
+	}
+}
+
+// WithTempDir stages media downloaded from links in dir instead of the OS default
+// temp dir. Downloads are still removed once uploaded. If dir is not writable when
+// the client is created, posting from links returns that error.
+func WithTempDir(dir string) Option {
+	return func(c *client) {
+		c.reddit.setTempDir(dir)
+	}
+}
+
+// WithDryRun validates requests and uploads their media without creating posts.
+// The oauth token request, the asset lease, and the media upload still happen;
+// the submission and the wait for it to succeed are skipped, and DryRunName is
+// returned in place of the post's fullname.
+func WithDryRun(dryRun bool) Option {
+	return func(c *client) {
+		c.reddit.setDryRun(dryRun)
+	}
+}
+
+// WithAutoNSFW marks every post to an 18+ subreddit as nsfw, even when the request
+// doesn't. Whether a subreddit is 18+ is fetched once from /r/<subreddit>/about and
+// cached. Posting to an 18+ subreddit without nsfw otherwise isn't rejected; the
+// post is just not marked.
+func WithAutoNSFW(autoNSFW bool) Option {
+	return func(c *client) {
+		c.autoNSFW = autoNSFW
+	}
+}
+
+// WithSubredditPrecheck checks that a subreddit exists and is public, through
+// /r/<subreddit>/about, before posting to it. This turns the opaque submit failure
+// for a missing, banned, or private subreddit into an immediate, clear error at
+// the cost of an extra request per subreddit.
+func WithSubredditPrecheck(precheck bool) Option {
+	return func(c *client) {
+		c.subredditPrecheck = precheck
+	}
+}
+
+// WithFlairPrecheck checks, before uploading any media, that a post's FlairID is
+// one the subreddit offers or, for a post without a FlairID, that the subreddit
+// doesn't require flair. This saves uploading media for a post reddit would reject.
+func WithFlairPrecheck(precheck bool) Option {
+	return func(c *client) {
+		c.flairPrecheck = precheck
+	}
+}
+
+// WithRepostCheck fails a link post made with Submit whose url was already posted
+// to the subreddit with a *DuplicateError, before submitting it. Reddit has no way
+// to validate a submit without making it, so the check looks the url up instead,
+// which only finds link posts of that exact url. Image and video posts aren't
+// checked: their media is uploaded to a new url each time.
+func WithRepostCheck(check bool) Option {
+	return func(c *client) {
+		c.repostCheck = check
+	}
+}
+
+// WithDuplicateProtection makes a repeated PostImage, PostVideo, or PostToSubreddits,
+// with the same subreddit, title, and media, within window return the post already
+// created instead of submitting it again. This is for retrying a post whose submit
+// reached reddit but whose confirmation was lost: such a post is looked up among
+// the user's newest posts, and submitted again only if it isn't found there.
+//
+// Submits are only remembered by this client, in memory, so the protection
+// doesn't extend across clients or restarts, and reddit may take a moment to list
+// a new post. It needs the username the client was created with.
+func WithDuplicateProtection(window time.Duration) Option {
+	return func(c *client) {
+		c.duplicateWindow = window
+	}
+}
+
+// WithPostMetadata looks up each post PostImage, PostVideo, or PostToSubreddits
+// creates, to fill in the CreatedUTC and Author of its PostResult with reddit's
+// own record. It costs a request per post; a lookup that fails leaves them empty.
+func WithPostMetadata(fetch bool) Option {
+	return func(c *client) {
+		c.postMetadata = fetch
+	}
+}
+
+// WithVerifyPost waits delay after PostImage, PostVideo, or PostToSubreddits
+// creates a post, then looks it up to report in PostResult.Removed whether it was
+// removed right away, as automod or the spam filter often do. Removals after the
+// check aren't seen. It costs a request per post and holds the call up by delay.
+func WithVerifyPost(delay time.Duration) Option {
+	return func(c *client) {
+		c.verifyDelay = delay
+	}
+}
+
+// WithUploadInterval waits at least interval, plus up to a quarter of it at
+// random, between starting the uploads of a gallery's items, so a large gallery
+// doesn't trip reddit's rate limits. Uploads already started still run at the
+// same time.
+func WithUploadInterval(interval time.Duration) Option {
+	return func(c *client) {
+		c.uploadInterval = interval
+	}
+}
+
+// WithDefaultNSFW marks a post nsfw when its request leaves NSWF nil.
+func WithDefaultNSFW(nsfw bool) Option {
+	return func(c *client) {
+		c.defaultNSFW = nsfw
+	}
+}
+
+// WithDefaultResubmit sets resubmit for a post whose request leaves Resubmit nil,
+// letting a link already posted to the subreddit be posted again.
+func WithDefaultResubmit(resubmit bool) Option {
+	return func(c *client) {
+		c.defaultResubmit = resubmit
+	}
+}
+
+// WithDefaultSendReplies sends replies to a post to the user's inbox when its
+// request leaves SendReplies nil.
+func WithDefaultSendReplies(sendReplies bool) Option {
+	return func(c *client) {
+		c.defaultSendReplies = sendReplies
+	}
+}
+
 type client struct {
-	reddit *reddit
+	reddit            *reddit
+	autoNSFW          bool
+	subredditPrecheck bool
+	flairPrecheck     bool
+	repostCheck       bool
+	duplicateWindow   time.Duration
+	uploadInterval    time.Duration
+	postMetadata      bool
+	verifyDelay       time.Duration
+
+	defaultNSFW        bool
+	defaultResubmit    bool
+	defaultSendReplies bool
+
+	recentMu sync.Mutex
+	recent   map[string]recentSubmit
 }
 
 func New(userAgent, clientID, secret, username, password string, options ...Option) Client {
+	return newClient(newReddit(userAgent, clientID, secret, username, password), options)
+}
+
+// NewApplicationOnly returns a Client that authenticates as the application alone,
+// with the client_credentials grant, instead of as a user. Application-only
+// tokens can't submit or manage posts; they are for reading public data.
+func NewApplicationOnly(userAgent, clientID, secret string, options ...Option) Client {
+	r := newReddit(userAgent, clientID, secret, "", "")
+	r.setApplicationOnly(true)
+	return newClient(r, options)
+}
+
+func newClient(r *reddit, options []Option) *client {
 	c := &client{
-		reddit: newReddit(userAgent, clientID, secret, username, password),
+		reddit: r,
 	}
 
 	for _, o := range options {
 		o(c)
 	}
+	c.reddit.applyProxy()
+	c.reddit.applyInsecureSkipVerify()
+	c.reddit.endpointErr = c.reddit.validateEndpoints()
 	return c
 }
 
-type PostImageRequest struct {
-	FlairID     string
-	FlairText   string
-	NSWF        bool
-	Path        string
-	Resubmit    bool
-	SendReplies bool
-	Spoiler     bool
-	Subreddit   string
-	Title       string
+func (c *client) TokenExpiry() time.Time {
+	return c.reddit.expiry()
+}
+
+func (c *client) LastRateLimit() (RateLimitInfo, bool) {
+	return c.reddit.lastRateLimit()
 }
 
-func (c *client) PostImage(ctx context.Context, req PostImageRequest) (string, error) {
-	if req.Path == "" {
-		return "", fmt.Errorf("must proivde a local path or link to image")
+// PostResult describes a submitted media post. A post whose submit fails after its
+// media was uploaded returns the asset ids alongside the error; under a
+// *WebsocketError the post may still have been created.
+type PostResult struct {
+	// Name is the fullname of the post, such as t3_x2dx7f.
+	Name string
+	// AssetID is the id of the uploaded image or video, for referencing the media
+	// elsewhere.
+	AssetID string
+	// ThumbnailAssetID is the id of the uploaded thumbnail of a video post.
+	ThumbnailAssetID string
+	// DownloadPaths are where media downloaded from links was kept, under
+	// WithKeepDownloads.
+	DownloadPaths []string
+	// CreatedUTC and Author are when and as whom reddit created the post, under
+	// WithPostMetadata or WithVerifyPost.
+	CreatedUTC time.Time
+	Author     string
+	// Removed is whether the post had been removed, such as by automod, when
+	// WithVerifyPost checked it. RemovalChecked is false if the check didn't run
+	// or failed.
+	Removed        bool
+	RemovalChecked bool
+	// WebsocketURL is where reddit reports the outcome of an image or video post.
+	// Under WithAsyncSubmit, WaitForPost waits on it for the post's fullname.
+	WebsocketURL string
+}
+
+func (c *client) UploadMedia(ctx context.Context, path string) (Asset, error) {
+	if path == "" {
+		return Asset{}, fmt.Errorf("must provide a local path or link to media")
 	}
 
 	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return Asset{}, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	asset, err := c.reddit.UploadAsset(ctx, path, "")
+	if err != nil {
+		return Asset{}, fmt.Errorf("uploading asset: %w", err)
+	}
+
+	return asset, nil
+}
+
+func (c *client) Submit(ctx context.Context, fields url.Values) (string, error) {
+	if fields.Get("kind") == "" {
+		return "", fmt.Errorf("must provide the kind of post")
+	}
+
+	if fields.Get("sr") == "" {
+		return "", fmt.Errorf("must provide a subreddit")
+	}
+
+	err := validateTitle(fields.Get("title"))
+	if err != nil {
+		return "", err
+	}
+
+	err = c.reddit.SetToken(ctx)
 	if err != nil {
 		return "", fmt.Errorf("setting oauth token: %w", err)
 	}
 
-	asset, err := c.reddit.UploadAsset(ctx, req.Path)
+	if fields.Get("kind") == "link" {
+		err = c.checkRepost(ctx, fields.Get("sr"), fields.Get("url"))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name, _, err := c.submit(ctx, "", fields)
+	if err != nil {
+		return "", fmt.Errorf("submitting post: %w", err)
+	}
+	return name, nil
+}
+
+func (c *client) WaitForPost(ctx context.Context, websocketURL string) (string, error) {
+	if websocketURL == "" {
+		return "", fmt.Errorf("must provide a websocket url")
+	}
+	return c.reddit.waitForPost(ctx, websocketURL)
+}
+
+// downloadPaths returns where the downloads of assets were kept.
+func downloadPaths(assets ...Asset) []string {
+	var paths []string
+	for _, a := range assets {
+		if a.DownloadPath != "" {
+			paths = append(paths, a.DownloadPath)
+		}
+	}
+	return paths
+}
+
+// Bool returns a pointer to v, for a request field such as SendReplies.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// boolOr returns v, or def when v is nil.
+func boolOr(v *bool, def bool) *bool {
+	if v != nil {
+		return v
+	}
+	return Bool(def)
+}
+
+// boolValue returns the value v points to, or false when v is nil.
+func boolValue(v *bool) bool {
+	return v != nil && *v
+}
+
+// postError names the subreddit and title of the post that failed with err.
+func postError(subreddit, title string, err error) error {
+	return fmt.Errorf("posting %q to r/%s: %w", title, subreddit, err)
+}
+
+type PostImageRequest struct {
+	// AsGallery posts the image as a gallery of one, which can have a Caption and
+	// OutboundURL, instead of as an image post.
+	AsGallery bool
+	Caption   string
+	// Extra holds submit fields redmed doesn't model, sent as given. It can't set
+	// the fields redmed sets itself.
+	Extra map[string]string
+	// Filename, such as cat.jpeg, names the uploaded image in place of the base of
+	// Path. The extension of Path is added if it has none.
+	Filename string
+	FlairID  string
+	// FlairName picks the subreddit's flair with this text, ignoring case, in place
+	// of FlairID. The subreddit's flairs are fetched once and cached.
+	FlairName string
+	FlairText string
+	// MimeType, such as image/webp, overrides the mime type looked up from the
+	// extension of Path.
+	MimeType string
+	// NSWF, Resubmit, and SendReplies fall back to the client's defaults, set with
+	// WithDefaultNSFW, WithDefaultResubmit, and WithDefaultSendReplies, when nil.
+	NSWF            *bool
+	OriginalContent bool
+	OutboundURL     string
+	Path            string
+	Resubmit        *bool
+	SendReplies     *bool
+	Spoiler         bool
+	Subreddit       string
+	Title           string
+}
+
+func (c *client) PostImage(ctx context.Context, req PostImageRequest) (PostResult, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.Resubmit = boolOr(req.Resubmit, c.defaultResubmit)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	result, err := c.postImage(ctx, req)
+	if err != nil {
+		return result, postError(req.Subreddit, req.Title, err)
+	}
+
+	c.addPostMetadata(ctx, &result)
+	return result, nil
+}
+
+func (c *client) postImage(ctx context.Context, req PostImageRequest) (PostResult, error) {
+	err := ValidateImageRequest(req)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	if req.AsGallery {
+		name, err := c.postGallery(ctx, PostGalleryRequest{
+			Captions:        []string{req.Caption},
+			Extra:           req.Extra,
+			FlairID:         req.FlairID,
+			FlairName:       req.FlairName,
+			FlairText:       req.FlairText,
+			NSWF:            req.NSWF,
+			OriginalContent: req.OriginalContent,
+			OutboundURLs:    []string{req.OutboundURL},
+			Paths:           []string{req.Path},
+			SendReplies:     req.SendReplies,
+			Spoiler:         req.Spoiler,
+			Subreddit:       req.Subreddit,
+			Title:           req.Title,
+		})
+		return PostResult{Name: name}, err
+	}
+
+	// reddit can't take a gif this large as an image, only as a videogif
+	largeGIF, err := c.isLargeGIF(ctx, req.Path, req.MimeType)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	if largeGIF {
+		return c.postVideo(ctx, PostVideoRequest{
+			Extra:           req.Extra,
+			Filename:        req.Filename,
+			FlairID:         req.FlairID,
+			FlairName:       req.FlairName,
+			FlairText:       req.FlairText,
+			Kind:            VideoKindGif,
+			MimeType:        req.MimeType,
+			NSWF:            req.NSWF,
+			OriginalContent: req.OriginalContent,
+			VideoPath:       req.Path,
+			Resubmit:        req.Resubmit,
+			SendReplies:     req.SendReplies,
+			Spoiler:         req.Spoiler,
+			Subreddit:       req.Subreddit,
+			Title:           req.Title,
+		})
+	}
+
+	err = c.reddit.SetToken(ctx)
 	if err != nil {
-		return "", fmt.Errorf("uploading asset: %w", err)
+		return PostResult{}, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	key := submitKey("image", req.Subreddit, req.Title, req.Path)
+	name, ok, err := c.findRecentSubmit(ctx, key, req.Subreddit, req.Title)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	if ok {
+		return PostResult{Name: name}, nil
 	}
 
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	asset, err := c.reddit.uploadAsset(ctx, req.Path, req.Filename, req.MimeType, nil)
+	if err != nil {
+		return PostResult{}, fmt.Errorf("uploading asset: %w", err)
+	}
+
+	name, websocketURL, err := c.submitPost(ctx, key, asset.WebSocket, imageForm(req, asset.Location, nsfw))
+	if err != nil {
+		return PostResult{AssetID: asset.ID}, fmt.Errorf("submitting post of asset %s: %w", asset.ID, err)
+	}
+
+	return PostResult{Name: name, AssetID: asset.ID, DownloadPaths: downloadPaths(asset), WebsocketURL: websocketURL}, nil
+}
+
+// imageForm returns the /api/submit form of an image post of the media uploaded
+// to location.
+func imageForm(req PostImageRequest, location string, nsfw bool) url.Values {
 	form := url.Values{}
 	form.Add("kind", "image")
 	form.Add("sr", req.Subreddit)
 	form.Add("title", req.Title)
-	form.Add("url", asset.Location)
-	form.Add("nsfw", strconv.FormatBool(req.NSWF))
-	form.Add("resubmit", strconv.FormatBool(req.Resubmit))
-	form.Add("sendreplies", strconv.FormatBool(req.SendReplies))
+	form.Add("url", location)
+	form.Add("nsfw", strconv.FormatBool(nsfw))
+	form.Add("resubmit", strconv.FormatBool(boolValue(req.Resubmit)))
+	form.Add("sendreplies", strconv.FormatBool(boolValue(req.SendReplies)))
 	form.Add("spoiler", strconv.FormatBool(req.Spoiler))
 
 	if req.FlairID != "" {
@@ -94,136 +835,357 @@ func (c *client) PostImage(ctx context.Context, req PostImageRequest) (string, e
 		form.Add("flair_text", req.FlairText)
 	}
 
-	name, err := c.reddit.SubmitPost(ctx, asset.WebSocket, strings.NewReader(form.Encode()))
-	if err != nil {
-		return "", fmt.Errorf("submitting post: %w", err)
+	if req.OriginalContent {
+		form.Add("original_content", "true")
 	}
 
-	return name, nil
+	for k, v := range req.Extra {
+		form.Set(k, v)
+	}
+
+	return form
+}
+
+func videoForm(req PostVideoRequest, location, posterLocation string, nsfw bool) url.Values {
+	form := url.Values{}
+	form.Add("kind", string(req.Kind))
+	form.Add("sr", req.Subreddit)
+	form.Add("title", req.Title)
+	form.Add("url", location)
+	form.Add("video_poster_url", posterLocation)
+	form.Add("nsfw", strconv.FormatBool(nsfw))
+	form.Add("resubmit", strconv.FormatBool(boolValue(req.Resubmit)))
+	form.Add("sendreplies", strconv.FormatBool(boolValue(req.SendReplies)))
+	form.Add("spoiler", strconv.FormatBool(req.Spoiler))
+
+	if req.FlairID != "" {
+		form.Add("flair_id", req.FlairID)
+	}
+
+	if req.FlairText != "" {
+		form.Add("flair_text", req.FlairText)
+	}
+
+	if req.PosterWidth > 0 && req.PosterHeight > 0 {
+		form.Add("video_poster_width", strconv.Itoa(req.PosterWidth))
+		form.Add("video_poster_height", strconv.Itoa(req.PosterHeight))
+	}
+
+	if req.OriginalContent {
+		form.Add("original_content", "true")
+	}
+
+	for k, v := range req.Extra {
+		form.Set(k, v)
+	}
+
+	return form
+}
+
+// VideoKind is how a video post is shown.
+type VideoKind string
+
+const (
+	VideoKindVideo VideoKind = "video"
+	// VideoKindGif is a silent, looping video.
+	VideoKindGif VideoKind = "videogif"
+)
+
+// isLargeGIF reports whether the media at path is a gif larger than reddit takes
+// for an image post.
+func (c *client) isLargeGIF(ctx context.Context, path, mimeType string) (bool, error) {
+	if mimeType == "" {
+		mimeType, _ = mimeTypeOf(path)
+	}
+
+	if mimeType != "image/gif" {
+		return false, nil
+	}
+
+	size, err := c.reddit.mediaSize(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	return size > maxImageSize, nil
 }
 
 type PostVideoRequest struct {
-	FlairID       string
-	FlairText     string
-	Kind          string
-	NSWF          bool
-	VideoPath     string
-	Resubmit      bool
-	SendReplies   bool
-	Spoiler       bool
-	Subreddit     string
+	// Extra holds submit fields redmed doesn't model, sent as given. It can't set
+	// the fields redmed sets itself.
+	Extra map[string]string
+	// Filename names the uploaded video in place of the base of VideoPath, as it
+	// does for an image.
+	Filename string
+	FlairID  string
+	// FlairName picks the subreddit's flair with this text in place of FlairID.
+	FlairName string
+	FlairText string
+	// Kind defaults to VideoKindVideo, or VideoKindGif for a gif.
+	Kind VideoKind
+	// MimeType, such as video/webm, overrides the mime type looked up from the
+	// extension of VideoPath.
+	MimeType string
+	// NSWF, Resubmit, and SendReplies fall back to the client's defaults when nil.
+	NSWF            *bool
+	OriginalContent bool
+	// PosterWidth and PosterHeight are the dimensions of the thumbnail, which reddit
+	// renders it at the aspect ratio of. Neither is sent when they are 0.
+	PosterWidth  int
+	PosterHeight int
+	VideoPath    string
+	Resubmit     *bool
+	SendReplies  *bool
+	Spoiler      bool
+	Subreddit    string
+	// ThumbnailPath may be empty for a gif, whose first frame is used instead.
 	ThumbnailPath string
 	Title         string
 }
 
-func (c *client) PostVideo(ctx context.Context, req PostVideoRequest) (string, error) {
-	if req.VideoPath == "" {
-		return "", fmt.Errorf("must provide a local path or link to video")
+func (c *client) PostVideo(ctx context.Context, req PostVideoRequest) (PostResult, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.Resubmit = boolOr(req.Resubmit, c.defaultResubmit)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	result, err := c.postVideo(ctx, req)
+	if err != nil {
+		return result, postError(req.Subreddit, req.Title, err)
+	}
+
+	c.addPostMetadata(ctx, &result)
+	return result, nil
+}
+
+func (c *client) postVideo(ctx context.Context, req PostVideoRequest) (PostResult, error) {
+	err := ValidateVideoRequest(req)
+	if err != nil {
+		return PostResult{}, err
 	}
 
-	if req.ThumbnailPath == "" {
-		return "", fmt.Errorf("must provide a local path or link to thumbnail image")
+	videoType := req.MimeType
+	if videoType == "" {
+		videoType, _ = mimeTypeOf(req.VideoPath)
 	}
 
-	if req.Kind != "video" && req.Kind != "videogif" {
-		return "", fmt.Errorf("kind must be video or videogif")
+	// a gif's first frame is its thumbnail unless one is given
+	gifThumbnail := req.ThumbnailPath == "" && videoType == "image/gif"
+
+	if req.Kind == "" {
+		req.Kind = VideoKindVideo
+		if videoType == "image/gif" {
+			req.Kind = VideoKindGif
+		}
 	}
 
-	err := c.reddit.SetToken(ctx)
+	err = c.reddit.SetToken(ctx)
 	if err != nil {
-		return "", fmt.Errorf("setting oauth token: %w", err)
+		return PostResult{}, fmt.Errorf("setting oauth token: %w", err)
 	}
 
-	videoAsset, err := c.reddit.UploadAsset(ctx, req.VideoPath)
+	key := submitKey(string(req.Kind), req.Subreddit, req.Title, req.VideoPath, req.ThumbnailPath)
+	name, ok, err := c.findRecentSubmit(ctx, key, req.Subreddit, req.Title)
 	if err != nil {
-		return "", fmt.Errorf("uploading video asset: %w", err)
+		return PostResult{}, err
+	}
+
+	if ok {
+		return PostResult{Name: name}, nil
 	}
 
-	thumbnailAsset, err := c.reddit.UploadAsset(ctx, req.ThumbnailPath)
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
 	if err != nil {
-		return "", fmt.Errorf("uploading thumbnail asset: %w", err)
+		return PostResult{}, err
 	}
 
-	form := url.Values{}
-	form.Add("kind", req.Kind)
-	form.Add("sr", req.Subreddit)
-	form.Add("title", req.Title)
-	form.Add("url", videoAsset.Location)
-	form.Add("video_poster_url", thumbnailAsset.Location)
-	form.Add("nsfw", strconv.FormatBool(req.NSWF))
-	form.Add("resubmit", strconv.FormatBool(req.Resubmit))
-	form.Add("sendreplies", strconv.FormatBool(req.SendReplies))
-	form.Add("spoiler", strconv.FormatBool(req.Spoiler))
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return PostResult{}, err
+	}
 
-	if req.FlairID != "" {
-		form.Add("flair_id", req.FlairID)
+	// a gif link is downloaded here, to extract its thumbnail from
+	var gifLink string
+	if gifThumbnail {
+		gifFS := c.reddit.files()
+		if isValidURL(req.VideoPath) {
+			gifPath, err := c.reddit.downloadLink(ctx, req.VideoPath, nil)
+			if err != nil {
+				return PostResult{}, fmt.Errorf("downloading %s: %w", req.VideoPath, err)
+			}
+			defer os.Remove(gifPath)
+			gifLink, req.VideoPath = req.VideoPath, gifPath
+			gifFS = osFS{}
+		}
+
+		req.ThumbnailPath, err = c.reddit.extractGIFThumbnail(gifFS, req.VideoPath)
+		if err != nil {
+			return PostResult{}, fmt.Errorf("extracting thumbnail: %w", err)
+		}
+		defer os.Remove(req.ThumbnailPath)
 	}
 
-	if req.FlairText != "" {
-		form.Add("flair_text", req.FlairText)
+	var videoAsset Asset
+	if gifLink != "" {
+		videoAsset, err = c.reddit.uploadTempFile(ctx, req.VideoPath, uploadName(gifLink, req.Filename), req.MimeType)
+	} else {
+		videoAsset, err = c.reddit.uploadAsset(ctx, req.VideoPath, req.Filename, req.MimeType, nil)
+	}
+	if err != nil {
+		return PostResult{}, fmt.Errorf("uploading video asset: %w", err)
 	}
 
-	name, err := c.reddit.SubmitPost(ctx, videoAsset.WebSocket, strings.NewReader(form.Encode()))
+	var thumbnailAsset Asset
+	if gifThumbnail {
+		thumbnailAsset, err = c.reddit.uploadTempFile(ctx, req.ThumbnailPath, "", "")
+	} else {
+		thumbnailAsset, err = c.reddit.UploadAsset(ctx, req.ThumbnailPath, "")
+	}
 	if err != nil {
-		return "", fmt.Errorf("submitting post: %w", err)
+		return PostResult{}, fmt.Errorf("uploading thumbnail asset: %w", err)
 	}
 
-	return name, nil
+	if gifLink != "" && c.reddit.keepDir != "" {
+		videoAsset.DownloadPath, err = c.reddit.keepDownload(req.VideoPath, gifLink)
+		if err != nil {
+			return PostResult{}, err
+		}
+	}
+
+	form := videoForm(req, videoAsset.Location, thumbnailAsset.Location, nsfw)
+	name, websocketURL, err := c.submitPost(ctx, key, videoAsset.WebSocket, form)
+	if err != nil {
+		result := PostResult{AssetID: videoAsset.ID, ThumbnailAssetID: thumbnailAsset.ID}
+		return result, fmt.Errorf("submitting post of asset %s: %w", videoAsset.ID, err)
+	}
+
+	result := PostResult{
+		Name:             name,
+		AssetID:          videoAsset.ID,
+		ThumbnailAssetID: thumbnailAsset.ID,
+		DownloadPaths:    downloadPaths(videoAsset, thumbnailAsset),
+		WebsocketURL:     websocketURL,
+	}
+	return result, nil
 }
 
 type PostGalleryRequest struct {
-	FlairID     string
-	FlairText   string
-	NSWF        bool
-	Paths       []string
-	SendReplies bool
-	Spoiler     bool
-	Subreddit   string
-	Title       string
+	// Captions and OutboundURLs are shown with the item at the same index of
+	// Paths. Either may be shorter than Paths.
+	Captions []string
+	// Extra holds submit fields redmed doesn't model, sent as given. It can't set
+	// the fields redmed sets itself.
+	Extra   map[string]string
+	FlairID string
+	// FlairName picks the subreddit's flair with this text in place of FlairID.
+	FlairName string
+	FlairText string
+	// NSWF and SendReplies fall back to the client's defaults when nil.
+	NSWF            *bool
+	OriginalContent bool
+	OutboundURLs    []string
+	Paths           []string
+	// DownloadHeaders are sent when downloading the link at the same index of
+	// Paths, such as a token or referer a CDN needs. It may be shorter than Paths.
+	DownloadHeaders []http.Header
+	SendReplies     *bool
+	Spoiler         bool
+	Subreddit       string
+	Title           string
 }
 
 func (c *client) PostGallery(ctx context.Context, req PostGalleryRequest) (string, error) {
-	if len(req.Paths) == 0 {
-		return "", fmt.Errorf("must provide local paths or links to images")
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	result, err := c.postGallery(ctx, req)
+	if err != nil {
+		return "", postError(req.Subreddit, req.Title, err)
+	}
+	return result, nil
+}
+
+// galleryValue returns the value at index of values, which may be shorter than
+// the gallery's paths.
+func galleryValue(values []string, index int) string {
+	if index < len(values) {
+		return values[index]
 	}
+	return ""
+}
 
-	err := c.reddit.SetToken(ctx)
+func (c *client) postGallery(ctx context.Context, req PostGalleryRequest) (string, error) {
+	err := ValidateGalleryRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.reddit.SetToken(ctx)
 	if err != nil {
 		return "", fmt.Errorf("setting oauth token: %w", err)
 	}
 
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return "", err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return "", err
+	}
+
 	items := make([]map[string]string, len(req.Paths))
+	galleryItems := make([]GalleryItem, len(req.Paths))
+
+	// every upload runs to completion so that all of the failed items are reported
+	uploadErrs := make([]error, len(req.Paths))
 
 	var eg errgroup.Group
 	for i, path := range req.Paths {
+		if i > 0 {
+			err = c.paceUpload(ctx)
+			if err != nil {
+				eg.Wait()
+				return "", fmt.Errorf("uploading assets: %w", err)
+			}
+		}
+
 		path := path
 		index := i
+
+		var header http.Header
+		if index < len(req.DownloadHeaders) {
+			header = req.DownloadHeaders[index]
+		}
+
 		eg.Go(func() error {
-			asset, err := c.reddit.UploadAsset(ctx, path)
+			asset, err := c.reddit.uploadAsset(ctx, path, "", "", header)
 			if err != nil {
-				return err
+				uploadErrs[index] = fmt.Errorf("item %d (%s): %w", index, path, err)
+				return nil
 			}
 
 			items[index] = map[string]string{
-				"caption":      "",
-				"outbound_url": "",
+				"caption":      galleryValue(req.Captions, index),
+				"outbound_url": galleryValue(req.OutboundURLs, index),
 				"media_id":     asset.ID,
 			}
+			galleryItems[index] = GalleryItem{Path: path, MediaID: asset.ID}
 			return nil
 		})
 	}
+	eg.Wait()
 
-	err = eg.Wait()
+	err = errors.Join(uploadErrs...)
 	if err != nil {
-		return "", fmt.Errorf("uploading asset: %w", err)
+		return "", fmt.Errorf("uploading assets: %w", err)
 	}
 
 	payload := map[string]interface{}{
 		"sr":                 req.Subreddit,
 		"title":              req.Title,
 		"items":              items,
-		"nsfw":               strconv.FormatBool(req.NSWF),
-		"sendreplies":        strconv.FormatBool(req.SendReplies),
+		"nsfw":               strconv.FormatBool(nsfw),
+		"sendreplies":        strconv.FormatBool(boolValue(req.SendReplies)),
 		"spoiler":            strconv.FormatBool(req.Spoiler),
 		"api_type":           "json",
 		"show_error_list":    true,
@@ -238,15 +1200,189 @@ func (c *client) PostGallery(ctx context.Context, req PostGalleryRequest) (strin
 		payload["flair_text"] = req.FlairText
 	}
 
+	if req.OriginalContent {
+		payload["original_content"] = true
+	}
+
+	for k, v := range req.Extra {
+		payload[k] = v
+	}
+
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("marshalling payload: %w", err)
 	}
 
 	name, err := c.reddit.SubmitGalleryPost(ctx, bytes.NewReader(payloadBytes))
+	if err != nil {
+		// a rate limit is about the account, not any item
+		var rateLimitErr *RateLimitError
+		var apiErr *APIError
+		if !errors.As(err, &rateLimitErr) && errors.As(err, &apiErr) {
+			err = newGalleryError(galleryItems, apiErr)
+		}
+		return "", fmt.Errorf("submitting post: %w", err)
+	}
+
+	return name, nil
+}
+
+// paceUpload waits out the upload interval, with jitter so concurrent galleries
+// don't start their uploads in lockstep, or until ctx is done.
+func (c *client) paceUpload(ctx context.Context) error {
+	if c.uploadInterval <= 0 {
+		return nil
+	}
+
+	wait := c.uploadInterval + time.Duration(rand.Int63n(int64(c.uploadInterval)/4+1))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type PostPollRequest struct {
+	// Duration is how many days the poll is open for, from 1 to 7.
+	Duration int
+	// Extra holds submit fields redmed doesn't model, sent as given. It can't set
+	// the fields redmed sets itself.
+	Extra   map[string]string
+	FlairID string
+	// FlairName picks the subreddit's flair with this text in place of FlairID.
+	FlairName string
+	FlairText string
+	// NSWF and SendReplies fall back to the client's defaults when nil.
+	NSWF *bool
+	// OptionImages are the local paths or links of images shown with Options, by
+	// index. An option whose image is "" or missing is text only.
+	OptionImages []string
+	Options      []string
+	SendReplies  *bool
+	Spoiler      bool
+	Subreddit    string
+	Text         string
+	Title        string
+}
+
+func (c *client) PostPoll(ctx context.Context, req PostPollRequest) (string, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	result, err := c.postPoll(ctx, req)
+	if err != nil {
+		return "", postError(req.Subreddit, req.Title, err)
+	}
+	return result, nil
+}
+
+func (c *client) postPoll(ctx context.Context, req PostPollRequest) (string, error) {
+	err := ValidatePollRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return "", err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return "", err
+	}
+
+	options, err := c.pollOptions(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]interface{}{
+		"sr":                 req.Subreddit,
+		"title":              req.Title,
+		"text":               req.Text,
+		"options":            options,
+		"duration":           req.Duration,
+		"nsfw":               strconv.FormatBool(nsfw),
+		"sendreplies":        strconv.FormatBool(boolValue(req.SendReplies)),
+		"spoiler":            strconv.FormatBool(req.Spoiler),
+		"api_type":           "json",
+		"show_error_list":    true,
+		"validate_on_submit": true,
+	}
+
+	if req.FlairID != "" {
+		payload["flair_id"] = req.FlairID
+	}
+
+	if req.FlairText != "" {
+		payload["flair_text"] = req.FlairText
+	}
+
+	for k, v := range req.Extra {
+		payload[k] = v
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshalling payload: %w", err)
+	}
+
+	name, err := c.reddit.SubmitPollPost(ctx, bytes.NewReader(payloadBytes))
 	if err != nil {
 		return "", fmt.Errorf("submitting post: %w", err)
 	}
 
 	return name, nil
 }
+
+// pollOptions returns the options of the poll payload for req: its texts or,
+// once any option has an image, each text with the media id of its uploaded
+// image, the way a gallery item refers to its image.
+func (c *client) pollOptions(ctx context.Context, req PostPollRequest) (interface{}, error) {
+	if len(req.OptionImages) == 0 {
+		return req.Options, nil
+	}
+
+	options := make([]map[string]string, len(req.Options))
+	for i, text := range req.Options {
+		options[i] = map[string]string{"text": text}
+	}
+
+	// the images upload at once, as a gallery's do, but the first failed upload
+	// cancels the ones still in flight: a poll has few options, so naming the
+	// first bad one is enough
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, path := range req.OptionImages {
+		if path == "" {
+			continue
+		}
+
+		path := path
+		index := i
+		eg.Go(func() error {
+			asset, err := c.reddit.UploadAsset(egCtx, path, "")
+			if err != nil {
+				return fmt.Errorf("option %d (%s): %w", index, path, err)
+			}
+
+			options[index]["media_id"] = asset.ID
+			return nil
+		})
+	}
+
+	err := eg.Wait()
+	if err != nil {
+		return nil, fmt.Errorf("uploading option images: %w", err)
+	}
+	return options, nil
+}
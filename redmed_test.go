@@ -4,12 +4,20 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -76,15 +84,7 @@ func TestPostImage(t *testing.T) {
 					w.Write(b)
 				case "/api/media/asset.json":
 					alr := assetLeaseResponse{}
-					alr.Args.Fields = []struct {
-						Name  string `json:"name"`
-						Value string `json:"value"`
-					}{
-						{
-							"name",
-							"value",
-						},
-					}
+					alr.Args.Fields = []leaseField{{Name: "name", Value: "value"}}
 
 					alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
 					alr.Asset.AssedID = "123"
@@ -114,8 +114,9 @@ func TestPostImage(t *testing.T) {
 			tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
 
 			// Given
-			dialer := websocket.DefaultDialer
-			dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			// a dialer of its own, since the mock servers are self-signed and changing
+			// websocket.DefaultDialer would hide a dialer option being ignored
+			dialer := &websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 
 			client := &http.Client{
 				Transport: &http.Transport{
@@ -129,25 +130,29 @@ func TestPostImage(t *testing.T) {
 			)
 
 			req := PostImageRequest{
-				NSWF:        false,
+				NSWF:        Bool(false),
 				Path:        "testdata/testimg.jpeg",
-				Resubmit:    true,
-				SendReplies: true,
+				Resubmit:    Bool(true),
+				SendReplies: Bool(true),
 				Spoiler:     false,
 				Subreddit:   "subreddit",
 				Title:       "image test",
 			}
 
 			// When
-			name, err := reddit.PostImage(context.Background(), req)
+			result, err := reddit.PostImage(context.Background(), req)
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			// Then
 			want := "t3_x1qxro"
-			if name != want {
-				t.Errorf("want %s, got %s", want, name)
+			if result.Name != want {
+				t.Errorf("want %s, got %s", want, result.Name)
+			}
+
+			if result.AssetID != "123" {
+				t.Errorf("want asset id 123, got %s", result.AssetID)
 			}
 		})
 		t.Run("Link", func(t *testing.T) {
@@ -222,15 +227,7 @@ func TestPostImage(t *testing.T) {
 					w.Write(b)
 				case "/api/media/asset.json":
 					alr := assetLeaseResponse{}
-					alr.Args.Fields = []struct {
-						Name  string `json:"name"`
-						Value string `json:"value"`
-					}{
-						{
-							"name",
-							"value",
-						},
-					}
+					alr.Args.Fields = []leaseField{{Name: "name", Value: "value"}}
 
 					alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
 					alr.Asset.AssedID = "123"
@@ -259,8 +256,9 @@ func TestPostImage(t *testing.T) {
 			tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
 
 			// Given
-			dialer := websocket.DefaultDialer
-			dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			// a dialer of its own, since the mock servers are self-signed and changing
+			// websocket.DefaultDialer would hide a dialer option being ignored
+			dialer := &websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 
 			client := &http.Client{
 				Transport: &http.Transport{
@@ -273,28 +271,277 @@ func TestPostImage(t *testing.T) {
 			)
 
 			req := PostImageRequest{
-				NSWF:        false,
+				NSWF:        Bool(false),
 				Path:        fmt.Sprintf("%s/image.jpeg", linkSvr.URL),
-				Resubmit:    true,
-				SendReplies: true,
+				Resubmit:    Bool(true),
+				SendReplies: Bool(true),
 				Spoiler:     false,
 				Subreddit:   "subreddit",
 				Title:       "image test",
 			}
 
 			// When
-			name, err := reddit.PostImage(context.Background(), req)
+			result, err := reddit.PostImage(context.Background(), req)
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			// Then
 			want := "t3_x1qxro"
-			if name != want {
-				t.Errorf("want %s, got %s", want, name)
+			if result.Name != want {
+				t.Errorf("want %s, got %s", want, result.Name)
 			}
 		})
 	})
+	t.Run("DryRun", func(t *testing.T) {
+		// action server. where the media is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. reddit api endpoints, without submission
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				resp := token{AccessToken: "token"}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(b)
+			case "/api/media/asset.json":
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+				err = json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Errorf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+			WithDryRun(true),
+		)
+
+		req := PostImageRequest{
+			Path:      "testdata/testimg.jpeg",
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		// When
+		result, err := reddit.PostImage(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if result.Name != DryRunName {
+			t.Errorf("want %s, got %s", DryRunName, result.Name)
+		}
+	})
+	t.Run("AsyncSubmit", func(t *testing.T) {
+		// action server. where the media is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. reddit api endpoints, with a websocket that is never dialed
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				resp := token{AccessToken: "token"}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(b)
+			case "/api/media/asset.json":
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+				alr.Asset.WebsocketURL = "wss://127.0.0.1:1"
+				err = json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			case "/api/submit":
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+			WithAsyncSubmit(true),
+		)
+
+		req := PostImageRequest{
+			Path:      "testdata/testimg.jpeg",
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		// When
+		result, err := reddit.PostImage(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if result.Name != "" || result.AssetID != "123" || result.WebsocketURL != "wss://127.0.0.1:1" {
+			t.Errorf("want only asset id 123 and the lease's websocket, got %+v", result)
+		}
+	})
+}
+
+func TestPostImageUnconfirmed(t *testing.T) {
+	// websocket server. closes without reporting the post
+	wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c.Close()
+	}))
+	defer wsSvr.Close()
+
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. accepts the submit
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			alr.Asset.WebsocketURL = "ws" + strings.TrimPrefix(wsSvr.URL, "http")
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(client))
+
+	req := PostImageRequest{
+		Path:      "testdata/testimg.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	// When
+	result, err := reddit.PostImage(context.Background(), req)
+
+	// Then
+	var wsErr *WebsocketError
+	if !errors.As(err, &wsErr) {
+		t.Fatalf("want *WebsocketError, got %v", err)
+	}
+
+	if result.Name != "" {
+		t.Errorf("want no fullname for an unconfirmed post, got %s", result.Name)
+	}
+
+	if result.AssetID != "123" {
+		t.Errorf("want the uploaded asset id with the error, got %q", result.AssetID)
+	}
 }
 
 func TestPostVideo(t *testing.T) {
@@ -359,15 +606,7 @@ func TestPostVideo(t *testing.T) {
 					w.Write(b)
 				case "/api/media/asset.json":
 					alr := assetLeaseResponse{}
-					alr.Args.Fields = []struct {
-						Name  string `json:"name"`
-						Value string `json:"value"`
-					}{
-						{
-							"name",
-							"value",
-						},
-					}
+					alr.Args.Fields = []leaseField{{Name: "name", Value: "value"}}
 
 					alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
 					alr.Asset.AssedID = "123"
@@ -397,8 +636,9 @@ func TestPostVideo(t *testing.T) {
 			tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
 
 			// Given
-			dialer := websocket.DefaultDialer
-			dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			// a dialer of its own, since the mock servers are self-signed and changing
+			// websocket.DefaultDialer would hide a dialer option being ignored
+			dialer := &websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 
 			client := &http.Client{
 				Transport: &http.Transport{
@@ -413,26 +653,26 @@ func TestPostVideo(t *testing.T) {
 
 			req := PostVideoRequest{
 				Kind:          "video",
-				NSWF:          false,
+				NSWF:          Bool(false),
 				VideoPath:     "testdata/video.mp4",
 				ThumbnailPath: "testdata/testimg.jpeg",
-				Resubmit:      true,
-				SendReplies:   true,
+				Resubmit:      Bool(true),
+				SendReplies:   Bool(true),
 				Spoiler:       false,
 				Subreddit:     "subreddit",
 				Title:         "video test",
 			}
 
 			// When
-			name, err := reddit.PostVideo(context.Background(), req)
+			result, err := reddit.PostVideo(context.Background(), req)
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			// Then
 			want := "t3_x1qxro"
-			if name != want {
-				t.Errorf("want %s, got %s", want, name)
+			if result.Name != want {
+				t.Errorf("want %s, got %s", want, result.Name)
 			}
 		})
 		t.Run("Link", func(t *testing.T) {
@@ -507,15 +747,7 @@ func TestPostVideo(t *testing.T) {
 					w.Write(b)
 				case "/api/media/asset.json":
 					alr := assetLeaseResponse{}
-					alr.Args.Fields = []struct {
-						Name  string `json:"name"`
-						Value string `json:"value"`
-					}{
-						{
-							"name",
-							"value",
-						},
-					}
+					alr.Args.Fields = []leaseField{{Name: "name", Value: "value"}}
 
 					alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
 					alr.Asset.AssedID = "123"
@@ -544,8 +776,9 @@ func TestPostVideo(t *testing.T) {
 			tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
 
 			// Given
-			dialer := websocket.DefaultDialer
-			dialer.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+			// a dialer of its own, since the mock servers are self-signed and changing
+			// websocket.DefaultDialer would hide a dialer option being ignored
+			dialer := &websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 
 			client := &http.Client{
 				Transport: &http.Transport{
@@ -559,29 +792,50 @@ func TestPostVideo(t *testing.T) {
 
 			req := PostVideoRequest{
 				Kind:          "video",
-				NSWF:          false,
+				NSWF:          Bool(false),
 				VideoPath:     fmt.Sprintf("%s/video.mp4", linkSvr.URL),
 				ThumbnailPath: "testdata/testimg.jpeg",
-				Resubmit:      true,
-				SendReplies:   true,
+				Resubmit:      Bool(true),
+				SendReplies:   Bool(true),
 				Spoiler:       false,
 				Subreddit:     "subreddit",
 				Title:         "image test",
 			}
 
 			// When
-			name, err := reddit.PostVideo(context.Background(), req)
+			result, err := reddit.PostVideo(context.Background(), req)
 			if err != nil {
 				t.Fatal(err)
 			}
 
 			// Then
 			want := "t3_x1qxro"
-			if name != want {
-				t.Errorf("want %s, got %s", want, name)
+			if result.Name != want {
+				t.Errorf("want %s, got %s", want, result.Name)
 			}
 		})
 	})
+	t.Run("ThumbnailNotImage", func(t *testing.T) {
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		req := PostVideoRequest{
+			Kind:          "video",
+			VideoPath:     "testdata/video.mp4",
+			ThumbnailPath: "testdata/video.mp4",
+			Subreddit:     "subreddit",
+			Title:         "video test",
+		}
+
+		// When
+		_, err := reddit.PostVideo(context.Background(), req)
+
+		// Then
+		want := `posting "video test" to r/subreddit: thumbnail must be an image`
+		if err == nil || err.Error() != want {
+			t.Errorf("want %s, got %v", want, err)
+		}
+	})
 }
 
 func TestPostGallery(t *testing.T) {
@@ -590,7 +844,7 @@ func TestPostGallery(t *testing.T) {
 		linkSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			switch r.URL.Path {
 			case "/image.jpeg":
-				b, err := os.ReadFile("testdata/image.jpeg")
+				b, err := os.ReadFile("testdata/testimg.jpeg")
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -627,15 +881,7 @@ func TestPostGallery(t *testing.T) {
 				w.Write(b)
 			case "/api/media/asset.json":
 				alr := assetLeaseResponse{}
-				alr.Args.Fields = []struct {
-					Name  string `json:"name"`
-					Value string `json:"value"`
-				}{
-					{
-						"name",
-						"value",
-					},
-				}
+				alr.Args.Fields = []leaseField{{Name: "name", Value: "value"}}
 
 				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
 				alr.Asset.AssedID = "123"
@@ -644,7 +890,7 @@ func TestPostGallery(t *testing.T) {
 					t.Fatal(err)
 				}
 			case "/api/submit_gallery_post.json":
-				pgr := postGalleryResponse{}
+				pgr := submitResponse{}
 				pgr.JSON.Data.ID = "t3_x1qxro"
 				err = json.NewEncoder(w).Encode(pgr)
 				if err != nil {
@@ -678,9 +924,9 @@ func TestPostGallery(t *testing.T) {
 		)
 
 		req := PostGalleryRequest{
-			NSWF:        false,
-			Paths:       []string{fmt.Sprintf("%s/video.mp4", linkSvr.URL), "testdata/testimg.jpeg"},
-			SendReplies: true,
+			NSWF:        Bool(false),
+			Paths:       []string{fmt.Sprintf("%s/image.jpeg", linkSvr.URL), "testdata/testimg.jpeg"},
+			SendReplies: Bool(true),
 			Spoiler:     false,
 			Subreddit:   "subreddit",
 			Title:       "image test",
@@ -698,4 +944,834 @@ func TestPostGallery(t *testing.T) {
 			t.Errorf("want %s, got %s", want, name)
 		}
 	})
+	t.Run("UploadFailuresNameItems", func(t *testing.T) {
+		// action server. where the media is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. reddit api endpoints
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				resp := token{AccessToken: "token"}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(b)
+			case "/api/media/asset.json":
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+				err = json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Fatalf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+		)
+
+		req := PostGalleryRequest{
+			Paths:     []string{"testdata/testimg.jpeg", "testdata/missing.jpeg", "testdata/missing.png"},
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// When
+		_, err = reddit.PostGallery(ctx, req)
+
+		// Then
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if strings.Contains(err.Error(), "item 0") {
+			t.Errorf("want error not naming item 0, got %v", err)
+		}
+
+		for _, want := range []string{"item 1 (testdata/missing.jpeg)", "item 2 (testdata/missing.png)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("want error naming %s, got %v", want, err)
+			}
+		}
+
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) {
+			t.Errorf("want error wrapping *fs.PathError, got %v", err)
+		}
+	})
+	t.Run("UploadInterval", func(t *testing.T) {
+		// action server. where the media is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. records when each upload starts
+		var mu sync.Mutex
+		var leased []time.Time
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				w.Write([]byte(`{"access_token": "token"}`))
+			case "/api/media/asset.json":
+				mu.Lock()
+				leased = append(leased, time.Now())
+				mu.Unlock()
+
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+				err := json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Fatalf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+			WithDryRun(true),
+			WithUploadInterval(50*time.Millisecond),
+		)
+
+		req := PostGalleryRequest{
+			Paths:     []string{"testdata/testimg.jpeg", "testdata/testimg.jpeg", "testdata/testimg.jpeg"},
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		// When
+		_, err = reddit.PostGallery(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, cancelErr := reddit.PostGallery(ctx, req)
+
+		// Then
+		if len(leased) < 3 {
+			t.Fatalf("want 3 uploads, got %d", len(leased))
+		}
+
+		for i := 1; i < 3; i++ {
+			if gap := leased[i].Sub(leased[i-1]); gap < 40*time.Millisecond {
+				t.Errorf("want uploads paced 50ms apart, upload %d started %s after the last", i, gap)
+			}
+		}
+
+		if !errors.Is(cancelErr, context.Canceled) {
+			t.Errorf("want the pacing to stop when the context is done, got %v", cancelErr)
+		}
+	})
+}
+
+func TestPostPoll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// reddit server. reddit api endpoints
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				resp := token{AccessToken: "token"}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(b)
+			case "/api/submit_poll_post.json":
+				var payload struct {
+					Options  []string `json:"options"`
+					Duration int      `json:"duration"`
+				}
+				err := json.NewDecoder(r.Body).Decode(&payload)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(payload.Options) != 2 || payload.Duration != 3 {
+					t.Errorf("unexpected payload %+v", payload)
+				}
+
+				pgr := submitResponse{}
+				pgr.JSON.Data.ID = "t3_x1qxro"
+				err = json.NewEncoder(w).Encode(pgr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Fatalf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+		)
+
+		req := PostPollRequest{
+			Duration:  3,
+			Options:   []string{"yes", "no"},
+			Subreddit: "subreddit",
+			Text:      "what do you think?",
+			Title:     "poll test",
+		}
+
+		// When
+		name, err := reddit.PostPoll(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		want := "t3_x1qxro"
+		if name != want {
+			t.Errorf("want %s, got %s", want, name)
+		}
+	})
+	t.Run("OptionImage", func(t *testing.T) {
+		// action server. where the option's image is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. reddit api endpoints
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				resp := token{AccessToken: "token"}
+				b, err := json.Marshal(resp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(b)
+			case "/api/media/asset.json":
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{
+					{
+						"name",
+						"value",
+					},
+				}
+
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+
+				err = json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			case "/api/submit_poll_post.json":
+				var payload struct {
+					Options []map[string]string `json:"options"`
+				}
+				err := json.NewDecoder(r.Body).Decode(&payload)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := []map[string]string{{"text": "cat", "media_id": "123"}, {"text": "dog"}}
+				if !reflect.DeepEqual(payload.Options, want) {
+					t.Errorf("want options %v, got %v", want, payload.Options)
+				}
+
+				pgr := submitResponse{}
+				pgr.JSON.Data.ID = "t3_x1qxro"
+				err = json.NewEncoder(w).Encode(pgr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			default:
+				t.Fatalf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		client := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password",
+			WithHTTPClient(client),
+		)
+
+		req := PostPollRequest{
+			Duration:     3,
+			OptionImages: []string{"testdata/testimg.jpeg"},
+			Options:      []string{"cat", "dog"},
+			Subreddit:    "subreddit",
+			Title:        "poll test",
+		}
+
+		// When
+		name, err := reddit.PostPoll(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		want := "t3_x1qxro"
+		if name != want {
+			t.Errorf("want %s, got %s", want, name)
+		}
+	})
+	t.Run("InvalidRequest", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  PostPollRequest
+		}{
+			{"TooFewOptions", PostPollRequest{Duration: 1, Options: []string{"yes"}, Title: "poll"}},
+			{"TooManyOptions", PostPollRequest{Duration: 1, Options: []string{"1", "2", "3", "4", "5", "6", "7"}, Title: "poll"}},
+			{"DurationTooShort", PostPollRequest{Duration: 0, Options: []string{"yes", "no"}, Title: "poll"}},
+			{"DurationTooLong", PostPollRequest{Duration: 8, Options: []string{"yes", "no"}, Title: "poll"}},
+			{"TooManyOptionImages", PostPollRequest{Duration: 1, OptionImages: []string{"a.jpeg", "b.jpeg", "c.jpeg"}, Options: []string{"yes", "no"}, Title: "poll"}},
+			{"OptionImageNotAnImage", PostPollRequest{Duration: 1, OptionImages: []string{"testdata/video.mp4"}, Options: []string{"yes", "no"}, Title: "poll"}},
+		}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := reddit.PostPoll(context.Background(), tc.req)
+				if err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+	})
+}
+
+func TestUploadMedia(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. reddit api endpoints, without submission
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			resp := token{AccessToken: "token"}
+			b, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(b)
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			alr.Asset.WebsocketURL = "wss://reddit.com/ws"
+			err = json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(client),
+	)
+
+	// When
+	asset, err := reddit.UploadMedia(context.Background(), "testdata/testimg.jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	want := Asset{
+		ID:        "123",
+		Location:  "https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91",
+		WebSocket: "wss://reddit.com/ws",
+	}
+
+	if asset != want {
+		t.Errorf("want %+v, got %+v", want, asset)
+	}
+}
+
+func TestUploadMediaFromFS(t *testing.T) {
+	media := []byte("png from an embedded file system")
+
+	// action server. checks the media uploaded is the one in the file system
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if string(b) != string(media) {
+			t.Errorf("want the media from the file system uploaded, got %s", b)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. reddit api endpoints, without submission
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			err = json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(client),
+		WithFS(fstest.MapFS{"media/image.png": &fstest.MapFile{Data: media}}),
+	)
+
+	// When
+	asset, err := reddit.UploadMedia(context.Background(), "media/image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if asset.ID != "123" {
+		t.Errorf("want asset 123, got %+v", asset)
+	}
+
+	_, err = reddit.UploadMedia(context.Background(), "testdata/testimg.jpeg")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("want paths outside the file system not found, got %v", err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	// websocket server. reports the media post submitted last is ready
+	wsSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x2dx7f/title/"}}`))
+	}))
+	defer wsSvr.Close()
+
+	// reddit server. creates self posts right away and media posts through the websocket
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := r.PostForm.Get("api_type"); got != "json" {
+				t.Errorf("want api_type json, got %s", got)
+			}
+
+			switch r.PostForm.Get("kind") {
+			case "self":
+				w.Write([]byte(`{"json": {"errors": [], "data": {"name": "t3_x1qxro", "url": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}}`))
+			default:
+				fmt.Fprintf(w, `{"json": {"errors": [], "data": {"websocket_url": "wss%s"}}}`, strings.TrimPrefix(wsSvr.URL, "https"))
+			}
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		kind     string
+		wantName string
+	}{
+		{"Self", "self", "t3_x1qxro"},
+		{"Media", "image", "t3_x2dx7f"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			reddit := New("userAgent", "clientID", "secret", "username", "password",
+				WithHTTPClient(client),
+				WithWebsocketDialer(&websocket.Dialer{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}),
+			)
+
+			fields := url.Values{
+				"kind":  {tc.kind},
+				"sr":    {"subreddit"},
+				"title": {"submit test"},
+			}
+
+			// When
+			name, err := reddit.Submit(context.Background(), fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if name != tc.wantName {
+				t.Errorf("want %s, got %s", tc.wantName, name)
+			}
+
+			if fields.Get("api_type") != "" {
+				t.Error("want fields left unchanged")
+			}
+		})
+	}
+}
+
+func TestPostVideoKind(t *testing.T) {
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-video.s3-accelerate.amazonaws.com/ttcn2fy0nyk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. records the submitted kind
+	var kind string
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+			kind = r.PostForm.Get("kind")
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		kind     VideoKind
+		wantKind string
+		wantErr  bool
+	}{
+		{"Default", "", "video", false},
+		{"Gif", VideoKindGif, "videogif", false},
+		{"Invalid", "gif", "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			kind = ""
+			reddit := New("userAgent", "clientID", "secret", "username", "password",
+				WithHTTPClient(client),
+				WithAsyncSubmit(true),
+			)
+
+			req := PostVideoRequest{
+				Kind:          tc.kind,
+				VideoPath:     "testdata/video.mp4",
+				ThumbnailPath: "testdata/testimg.jpeg",
+				Subreddit:     "subreddit",
+				Title:         "video test",
+			}
+
+			// When
+			_, err := reddit.PostVideo(context.Background(), req)
+
+			// Then
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if kind != tc.wantKind {
+				t.Errorf("want kind %s, got %s", tc.wantKind, kind)
+			}
+		})
+	}
+}
+
+func TestOriginalContent(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"Image", imageForm(PostImageRequest{OriginalContent: true}, "location", false), "true"},
+		{"ImageNotOC", imageForm(PostImageRequest{}, "location", false), ""},
+		{"Video", videoForm(PostVideoRequest{OriginalContent: true}, "location", "poster", false), "true"},
+		{"VideoNotOC", videoForm(PostVideoRequest{}, "location", "poster", false), ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.form.Get("original_content"); got != tc.want {
+				t.Errorf("want original_content %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVideoFormPosterDimensions(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        PostVideoRequest
+		wantWidth  string
+		wantHeight string
+	}{
+		{"Set", PostVideoRequest{PosterWidth: 1280, PosterHeight: 720}, "1280", "720"},
+		{"Unset", PostVideoRequest{}, "", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			form := videoForm(tc.req, "location", "poster", false)
+			if form.Get("video_poster_width") != tc.wantWidth || form.Get("video_poster_height") != tc.wantHeight {
+				t.Errorf("want poster %sx%s, got %sx%s", tc.wantWidth, tc.wantHeight, form.Get("video_poster_width"), form.Get("video_poster_height"))
+			}
+		})
+	}
+}
+
+func TestExtra(t *testing.T) {
+	tests := []struct {
+		name string
+		form url.Values
+	}{
+		{"Image", imageForm(PostImageRequest{Extra: map[string]string{"collection_id": "id"}}, "location", false)},
+		{"Video", videoForm(PostVideoRequest{Extra: map[string]string{"collection_id": "id"}}, "location", "poster", false)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.form.Get("collection_id"); got != "id" {
+				t.Errorf("want collection_id id, got %q", got)
+			}
+		})
+	}
 }
@@ -0,0 +1,258 @@
+// Package redmedtest provides a fake reddit for testing code that posts with
+// redmed, without network access or a reddit account.
+//
+//	mock := redmedtest.NewMockReddit()
+//	defer mock.Close()
+//
+//	reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+package redmedtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/atye/redmed"
+	"github.com/gorilla/websocket"
+)
+
+// Mock is a fake reddit made of three servers, like the real one: the api and
+// oauth token endpoints, the action server media is uploaded to, and the websocket
+// server that reports when a media post is ready.
+//
+// It hands out a token to any credentials, leases and accepts any upload, and
+// creates every post submitted, recording each so tests can check what was sent.
+// Handle replaces or adds an api endpoint, such as to make a submit fail.
+type Mock struct {
+	Reddit    *httptest.Server
+	Action    *httptest.Server
+	Websocket *httptest.Server
+
+	mu          sync.Mutex
+	handlers    map[string]http.HandlerFunc
+	mimeTypes   map[string]string
+	uploads     []Upload
+	submissions []Submission
+	posts       int
+}
+
+// Upload is media uploaded to the action server.
+type Upload struct {
+	// AssetID is the id of the lease the media was uploaded to.
+	AssetID  string
+	FileName string
+	// MimeType is the mime type the lease was asked for.
+	MimeType string
+	Size     int
+}
+
+// Submission is a post submitted to the mock.
+type Submission struct {
+	// Endpoint is the path submitted to, such as /api/submit or
+	// /api/submit_gallery_post.json.
+	Endpoint string
+	// Form holds the fields of a /api/submit.
+	Form url.Values
+	// JSON holds the payload of a gallery or poll submit.
+	JSON map[string]interface{}
+	// Name is the fullname of the post created, such as t3_mock1.
+	Name string
+}
+
+// NewMockReddit starts a fake reddit. Close it when the test is done.
+func NewMockReddit() *Mock {
+	m := &Mock{
+		handlers:  make(map[string]http.HandlerFunc),
+		mimeTypes: make(map[string]string),
+	}
+
+	m.Reddit = httptest.NewTLSServer(http.HandlerFunc(m.serveReddit))
+	m.Action = httptest.NewTLSServer(http.HandlerFunc(m.serveAction))
+	m.Websocket = httptest.NewTLSServer(http.HandlerFunc(m.serveWebsocket))
+	return m
+}
+
+// Close shuts down the servers.
+func (m *Mock) Close() {
+	m.Reddit.Close()
+	m.Action.Close()
+	m.Websocket.Close()
+}
+
+// Options point a redmed client at the mock: its api and token urls, and an http
+// client and websocket dialer that trust its self-signed certificates. Options
+// given after them to redmed.New override them.
+func (m *Mock) Options() []redmed.Option {
+	transport := m.Reddit.Client().Transport.(*http.Transport)
+
+	return []redmed.Option{
+		redmed.WithBaseURL(m.Reddit.URL),
+		redmed.WithTokenURL(m.Reddit.URL + "/api/v1/access_token"),
+		redmed.WithHTTPClient(&http.Client{Transport: transport.Clone()}),
+		redmed.WithWebsocketDialer(&websocket.Dialer{TLSClientConfig: transport.TLSClientConfig.Clone()}),
+	}
+}
+
+// Handle serves the api endpoint at path with h instead of the mock's own, or
+// adds it if the mock doesn't serve it, such as /r/subreddit/about.
+func (m *Mock) Handle(path string, h http.HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[path] = h
+}
+
+// Uploads returns the media uploaded so far, in order.
+func (m *Mock) Uploads() []Upload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Upload(nil), m.uploads...)
+}
+
+// Submissions returns the posts submitted so far, in order.
+func (m *Mock) Submissions() []Submission {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Submission(nil), m.submissions...)
+}
+
+func (m *Mock) serveReddit(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	h, ok := m.handlers[r.URL.Path]
+	m.mu.Unlock()
+
+	if ok {
+		h(w, r)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v1/access_token":
+		w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 3600, "scope": "*"}`))
+	case "/api/media/asset.json":
+		m.lease(w, r)
+	case "/api/submit":
+		m.submit(w, r)
+	case "/api/submit_gallery_post.json", "/api/submit_poll_post.json":
+		m.submitJSON(w, r)
+	default:
+		http.Error(w, fmt.Sprintf(`{"message": "%s not supported by the mock", "error": 404}`, r.URL.Path), http.StatusNotFound)
+	}
+}
+
+// lease leases an upload to the action server, keyed by the new asset's id.
+func (m *Mock) lease(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	assetID := fmt.Sprintf("asset%d", len(m.mimeTypes)+1)
+	m.mimeTypes[assetID] = r.PostForm.Get("mimetype")
+	m.mu.Unlock()
+
+	actionURL, _ := url.Parse(m.Action.URL)
+	websocketURL, _ := url.Parse(m.Websocket.URL)
+
+	fmt.Fprintf(w, `{
+		"args": {"action": "//%s", "fields": [{"name": "key", "value": "%s"}]},
+		"asset": {"asset_id": "%s", "websocket_url": "wss://%s/%s"}
+	}`, actionURL.Host, assetID, assetID, websocketURL.Host, assetID)
+}
+
+func (m *Mock) serveAction(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(32 << 20)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	size, err := io.Copy(io.Discard, file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	assetID := r.FormValue("key")
+
+	m.mu.Lock()
+	m.uploads = append(m.uploads, Upload{
+		AssetID:  assetID,
+		FileName: header.Filename,
+		MimeType: m.mimeTypes[assetID],
+		Size:     int(size),
+	})
+	m.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "<PostResponse><Location>%s/%s</Location></PostResponse>", m.Action.URL, assetID)
+}
+
+// submit creates a post from a /api/submit. A media post is confirmed on the
+// websocket and any other is created by the submit itself, as on reddit.
+func (m *Mock) submit(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := m.record(Submission{Endpoint: r.URL.Path, Form: r.PostForm})
+
+	switch r.PostForm.Get("kind") {
+	case "image", "video", "videogif":
+		websocketURL, _ := url.Parse(m.Websocket.URL)
+		fmt.Fprintf(w, `{"json": {"errors": [], "data": {"websocket_url": "wss://%s/%s"}}}`, websocketURL.Host, strings.TrimPrefix(name, "t3_"))
+	default:
+		fmt.Fprintf(w, `{"json": {"errors": [], "data": {"name": "%s"}}}`, name)
+	}
+}
+
+func (m *Mock) submitJSON(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := m.record(Submission{Endpoint: r.URL.Path, JSON: payload})
+	fmt.Fprintf(w, `{"json": {"errors": [], "data": {"id": "%s"}}}`, name)
+}
+
+// record records s as a new post and returns its fullname.
+func (m *Mock) record(s Submission) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.posts++
+	s.Name = fmt.Sprintf("t3_mock%d", m.posts)
+	m.submissions = append(m.submissions, s)
+	return s.Name
+}
+
+// serveWebsocket reports the post at the path of the websocket url as ready.
+func (m *Mock) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	id := strings.Trim(r.URL.Path, "/")
+	c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/%s/title/"}}`, id)))
+}
@@ -0,0 +1,119 @@
+package redmedtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/atye/redmed"
+	"github.com/atye/redmed/redmedtest"
+)
+
+func TestMockReddit(t *testing.T) {
+	t.Run("PostImage", func(t *testing.T) {
+		// Given
+		mock := redmedtest.NewMockReddit()
+		defer mock.Close()
+
+		reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+		req := redmed.PostImageRequest{
+			Path:      "../testdata/testimg.jpeg",
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		// When
+		result, err := reddit.PostImage(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if result.Name != "t3_mock1" {
+			t.Errorf("want the post confirmed on the websocket as t3_mock1, got %s", result.Name)
+		}
+
+		uploads := mock.Uploads()
+		if len(uploads) != 1 || uploads[0].FileName != "testimg.jpeg" || uploads[0].MimeType != "image/jpeg" || uploads[0].Size == 0 {
+			t.Errorf("want testimg.jpeg uploaded, got %+v", uploads)
+		}
+
+		submissions := mock.Submissions()
+		if len(submissions) != 1 {
+			t.Fatalf("want 1 submission, got %d", len(submissions))
+		}
+
+		if got := submissions[0].Form.Get("title"); got != "image test" {
+			t.Errorf("want title image test, got %s", got)
+		}
+	})
+	t.Run("PostGallery", func(t *testing.T) {
+		// Given
+		mock := redmedtest.NewMockReddit()
+		defer mock.Close()
+
+		reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+		req := redmed.PostGalleryRequest{
+			Paths:     []string{"../testdata/testimg.jpeg", "../testdata/testimg.jpeg"},
+			Subreddit: "subreddit",
+			Title:     "gallery test",
+		}
+
+		// When
+		name, err := reddit.PostGallery(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if name != "t3_mock1" {
+			t.Errorf("want t3_mock1, got %s", name)
+		}
+
+		if got := len(mock.Uploads()); got != 2 {
+			t.Errorf("want 2 uploads, got %d", got)
+		}
+
+		submissions := mock.Submissions()
+		if len(submissions) != 1 || submissions[0].Endpoint != "/api/submit_gallery_post.json" {
+			t.Fatalf("want a gallery submission, got %+v", submissions)
+		}
+
+		if items, _ := submissions[0].JSON["items"].([]interface{}); len(items) != 2 {
+			t.Errorf("want 2 gallery items, got %v", submissions[0].JSON["items"])
+		}
+	})
+	t.Run("Handle", func(t *testing.T) {
+		// Given
+		mock := redmedtest.NewMockReddit()
+		defer mock.Close()
+
+		mock.Handle("/api/submit", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"json": {"errors": [["RATELIMIT", "you are doing that too much", "ratelimit"]]}}`))
+		})
+
+		reddit := redmed.New("userAgent", "clientID", "secret", "username", "password", mock.Options()...)
+
+		req := redmed.PostImageRequest{
+			Path:      "../testdata/testimg.jpeg",
+			Subreddit: "subreddit",
+			Title:     "image test",
+		}
+
+		// When
+		_, err := reddit.PostImage(context.Background(), req)
+
+		// Then
+		var apiErr *redmed.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("want the handled submit's *APIError, got %v", err)
+		}
+
+		if got := len(mock.Submissions()); got != 0 {
+			t.Errorf("want the mock's own submit replaced, got %d submissions", got)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+)
+
+func (c *client) IsRepost(ctx context.Context, subreddit, link string) (bool, string, error) {
+	subreddit = normalizeSubreddit(subreddit)
+
+	if !isValidURL(link) {
+		return false, "", fmt.Errorf("%q is not a link", link)
+	}
+
+	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return false, "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	s, err := c.findLinkPost(ctx, subreddit, link)
+	if err != nil {
+		return false, "", fmt.Errorf("looking up %s in r/%s: %w", link, subreddit, err)
+	}
+
+	if s == nil {
+		return false, "", nil
+	}
+	return true, s.Permalink, nil
+}
+
+// checkRepost, with WithRepostCheck, returns a *DuplicateError when a link post of
+// link was already made in subreddit.
+func (c *client) checkRepost(ctx context.Context, subreddit, link string) error {
+	if !c.repostCheck || !isValidURL(link) {
+		return nil
+	}
+
+	s, err := c.findLinkPost(ctx, subreddit, link)
+	if err != nil {
+		return fmt.Errorf("checking for a repost: %w", err)
+	}
+
+	if s != nil {
+		return &DuplicateError{Subreddit: subreddit, URL: link, Name: s.Name, Permalink: s.Permalink}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newRepostTestClient returns a client talking to a reddit server whose only post
+// is a link to https://host.com/image.jpeg in r/subreddit. Submitting fails the
+// test.
+func newRepostTestClient(t *testing.T, options ...Option) Client {
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/info":
+			if r.URL.Query().Get("url") != "https://host.com/image.jpeg" {
+				w.Write([]byte(`{"data": {"children": []}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"children": [{"data": {"name": "t3_x1qxro", "subreddit": "subreddit", "permalink": "/r/subreddit/comments/x1qxro/image_test/"}}]}}`))
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	t.Cleanup(redditSvr.Close)
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	t.Cleanup(func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	})
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	return New("userAgent", "clientID", "secret", "username", "password", append([]Option{WithHTTPClient(httpClient)}, options...)...)
+}
+
+func TestIsRepost(t *testing.T) {
+	tests := []struct {
+		name          string
+		subreddit     string
+		link          string
+		want          bool
+		wantPermalink string
+	}{
+		{"Repost", "subreddit", "https://host.com/image.jpeg", true, "/r/subreddit/comments/x1qxro/image_test/"},
+		{"OtherSubreddit", "other", "https://host.com/image.jpeg", false, ""},
+		{"OtherLink", "subreddit", "https://host.com/other.jpeg", false, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			reddit := newRepostTestClient(t)
+
+			// When
+			got, permalink, err := reddit.IsRepost(context.Background(), tc.subreddit, tc.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Then
+			if got != tc.want || permalink != tc.wantPermalink {
+				t.Errorf("want %t %q, got %t %q", tc.want, tc.wantPermalink, got, permalink)
+			}
+		})
+	}
+}
+
+func TestRepostCheck(t *testing.T) {
+	// Given
+	reddit := newRepostTestClient(t, WithRepostCheck(true))
+
+	fields := url.Values{
+		"kind":  []string{"link"},
+		"sr":    []string{"subreddit"},
+		"title": []string{"link test"},
+		"url":   []string{"https://host.com/image.jpeg"},
+	}
+
+	// When
+	_, err := reddit.Submit(context.Background(), fields)
+
+	// Then
+	var dupErr *DuplicateError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("want *DuplicateError, got %v", err)
+	}
+
+	if dupErr.Name != "t3_x1qxro" {
+		t.Errorf("want t3_x1qxro, got %s", dupErr.Name)
+	}
+}
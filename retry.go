@@ -0,0 +1,75 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// retry calls fn until it succeeds, fails with an error that isn't worth retrying,
+// or has been retried as many times as WithRetry allows. The wait between attempts
+// doubles from the configured backoff.
+func (c *reddit) retry(ctx context.Context, fn func() error) error {
+	return retry(ctx, c.retries, c.retryBackoff, fn)
+}
+
+// retry calls fn until it succeeds, fails with an error that isn't worth retrying,
+// or has been retried retries times, waiting backoff, doubling, between attempts.
+func retry(ctx context.Context, retries int, backoff time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable reports whether err is a transient failure: a network error, or a
+// server error or rate limit response. A failure that would only repeat, such as a
+// url that doesn't parse, a certificate that doesn't verify, or a redirect
+// CheckRedirect refused, isn't.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode() >= http.StatusInternalServerError || statusErr.StatusCode() == http.StatusTooManyRequests
+	}
+
+	var redirectErr *redirectError
+	if errors.As(err, &redirectErr) || isTLSError(err) {
+		return false
+	}
+
+	// the http client reports every failure to send a request or read its response
+	// as a *url.Error, and so does parsing a url
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && urlErr.Op != "parse"
+}
+
+// isTLSError reports whether err is a tls handshake that failed on the server's
+// certificate, or because the server doesn't speak tls.
+func isTLSError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
@@ -0,0 +1,161 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUploadToLeaseRetry(t *testing.T) {
+	// action server. fails the first upload, then takes the whole file
+	var attempts int
+	actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if int64(len(b)) != r.ContentLength {
+			t.Errorf("want %d bytes, got %d", r.ContentLength, len(b))
+		}
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if !strings.Contains(string(b), `name="file"; filename="testimg.jpeg"`) {
+			t.Error("want file in replayed body")
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	// Given
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	c.setRetry(2, time.Millisecond)
+
+	// When
+	location, err := c.uploadToLease(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if attempts != 2 {
+		t.Errorf("want 2 attempts, got %d", attempts)
+	}
+
+	want := "https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91"
+	if location != want {
+		t.Errorf("want %s, got %s", want, location)
+	}
+}
+
+func TestUploadToLeaseNotRetried(t *testing.T) {
+	t.Run("RefusedRedirect", func(t *testing.T) {
+		// action server. redirects every upload
+		var attempts int
+		actionSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			io.Copy(io.Discard, r.Body)
+			http.Redirect(w, r, "/elsewhere", http.StatusSeeOther)
+		}))
+		defer actionSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setHTTPClient(&http.Client{
+			CheckRedirect: func(r *http.Request, via []*http.Request) error {
+				return errors.New("redirects not allowed")
+			},
+		})
+		c.setRetry(2, time.Millisecond)
+
+		// When
+		_, err := c.uploadToLease(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "redirects not allowed") {
+			t.Errorf("want the refused redirect, got %v", err)
+		}
+
+		if attempts != 1 {
+			t.Errorf("want 1 attempt, got %d", attempts)
+		}
+	})
+	t.Run("UnverifiedCertificate", func(t *testing.T) {
+		// action server. self-signed, counting the connections made to it
+		var connections int32
+		actionSvr := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("want no upload to an unverified server")
+		}))
+		actionSvr.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&connections, 1)
+			}
+		}
+		actionSvr.Config.ErrorLog = log.New(io.Discard, "", 0)
+		actionSvr.StartTLS()
+		defer actionSvr.Close()
+
+		// Given
+		c := newReddit("userAgent", "clientID", "secret", "username", "password")
+		c.setHTTPClient(&http.Client{Transport: &http.Transport{}})
+		c.setRetry(2, time.Millisecond)
+
+		// When
+		_, err := c.uploadToLease(context.Background(), actionSvr.URL, assetLeaseResponse{}, "testimg.jpeg", osFS{}, "testdata/testimg.jpeg")
+
+		// Then
+		if err == nil {
+			t.Error("want the self-signed server rejected")
+		}
+
+		if got := atomic.LoadInt32(&connections); got != 1 {
+			t.Errorf("want 1 attempt, got %d", got)
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"ServerError", &StatusError{statusCode: http.StatusBadGateway}, true},
+		{"TooManyRequests", &StatusError{statusCode: http.StatusTooManyRequests}, true},
+		{"BadRequest", &StatusError{statusCode: http.StatusBadRequest}, false},
+		{"Cancelled", context.Canceled, false},
+		{"Local", io.ErrUnexpectedEOF, false},
+		{"Network", &url.Error{Op: "Post", URL: "https://host.com", Err: io.ErrUnexpectedEOF}, true},
+		{"Parse", &url.Error{Op: "parse", URL: "://host.com", Err: errors.New("missing protocol scheme")}, false},
+		{"Certificate", &url.Error{Op: "Post", URL: "https://host.com", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, false},
+		{"NotTLS", &url.Error{Op: "Post", URL: "https://host.com", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, false},
+		{"Redirect", &redirectError{err: &url.Error{Op: "Post", URL: "https://host.com", Err: errors.New("redirects not allowed")}}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryable(tc.err); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package redmed
+
+import (
+	"context"
+	"time"
+)
+
+// SchedulePost waits until at and then calls post, returning its result. Reddit
+// has no scheduling api for third parties, so this only defers the call: the post
+// is created when post runs. A time in the past runs post right away, and ctx
+// being done before at returns ctx's error without calling post.
+//
+//	name, err := redmed.SchedulePost(ctx, at, func(ctx context.Context) (string, error) {
+//		result, err := reddit.PostImage(ctx, req)
+//		return result.Name, err
+//	})
+func SchedulePost(ctx context.Context, at time.Time, post func(context.Context) (string, error)) (string, error) {
+	if wait := time.Until(at); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return "", err
+	}
+	return post(ctx)
+}
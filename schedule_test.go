@@ -0,0 +1,71 @@
+package redmed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulePost(t *testing.T) {
+	post := func(called *time.Time) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) {
+			*called = time.Now()
+			return "t3_x1qxro", nil
+		}
+	}
+
+	t.Run("Future", func(t *testing.T) {
+		// Given
+		var called time.Time
+		at := time.Now().Add(50 * time.Millisecond)
+
+		// When
+		name, err := SchedulePost(context.Background(), at, post(&called))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if name != "t3_x1qxro" {
+			t.Errorf("want t3_x1qxro, got %s", name)
+		}
+
+		if called.Before(at) {
+			t.Errorf("want post called after %s, got %s", at, called)
+		}
+	})
+	t.Run("Past", func(t *testing.T) {
+		// Given
+		var called time.Time
+
+		// When
+		name, err := SchedulePost(context.Background(), time.Now().Add(-time.Hour), post(&called))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if name != "t3_x1qxro" || called.IsZero() {
+			t.Errorf("want post called, got %s", name)
+		}
+	})
+	t.Run("Cancelled", func(t *testing.T) {
+		// Given
+		var called time.Time
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// When
+		_, err := SchedulePost(ctx, time.Now().Add(time.Hour), post(&called))
+
+		// Then
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want context.DeadlineExceeded, got %v", err)
+		}
+
+		if !called.IsZero() {
+			t.Error("want post not called")
+		}
+	})
+}
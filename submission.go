@@ -0,0 +1,155 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Submission is the current state of a post.
+type Submission struct {
+	// Name is the post's fullname, such as t3_x2dx7f.
+	Name      string
+	Title     string
+	Author    string
+	Subreddit string
+	URL       string
+	Permalink string
+	Score     int
+	// Removed is true when the post was removed by a moderator, automod, or
+	// reddit's spam filters.
+	Removed    bool
+	CreatedUTC time.Time
+}
+
+type listing struct {
+	Data struct {
+		After    string `json:"after"`
+		Children []struct {
+			Data submissionData `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+type submissionData struct {
+	Name              string  `json:"name"`
+	Title             string  `json:"title"`
+	Author            string  `json:"author"`
+	Subreddit         string  `json:"subreddit"`
+	URL               string  `json:"url"`
+	Permalink         string  `json:"permalink"`
+	Score             int     `json:"score"`
+	Removed           bool    `json:"removed"`
+	RemovedByCategory string  `json:"removed_by_category"`
+	CreatedUTC        float64 `json:"created_utc"`
+}
+
+func (d submissionData) submission() Submission {
+	return Submission{
+		Name:       d.Name,
+		Title:      d.Title,
+		Author:     d.Author,
+		Subreddit:  d.Subreddit,
+		URL:        d.URL,
+		Permalink:  d.Permalink,
+		Score:      d.Score,
+		Removed:    d.Removed || d.RemovedByCategory != "",
+		CreatedUTC: time.Unix(int64(d.CreatedUTC), 0).UTC(),
+	}
+}
+
+func (c *client) GetSubmission(ctx context.Context, fullname string) (*Submission, error) {
+	err := validateFullname(fullname)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	var l listing
+	err = c.reddit.GetJSON(ctx, "/api/info", url.Values{"id": []string{fullname}}, &l)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s: %w", fullname, err)
+	}
+
+	if len(l.Data.Children) == 0 {
+		return nil, fmt.Errorf("%s not found", fullname)
+	}
+
+	s := l.Data.Children[0].Data.submission()
+	return &s, nil
+}
+
+// addPostMetadata fills in when and as whom reddit created the post of result
+// under WithPostMetadata, and whether it was removed under WithVerifyPost, after
+// waiting the verify delay. The post exists either way, so a failed lookup
+// leaves the fields empty rather than failing the post.
+func (c *client) addPostMetadata(ctx context.Context, result *PostResult) {
+	if !c.postMetadata && c.verifyDelay <= 0 || result.Name == "" || result.Name == DryRunName {
+		return
+	}
+
+	if c.verifyDelay > 0 {
+		timer := time.NewTimer(c.verifyDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	s, err := c.GetSubmission(ctx, result.Name)
+	if err != nil {
+		return
+	}
+
+	result.CreatedUTC = s.CreatedUTC
+	result.Author = s.Author
+
+	if c.verifyDelay > 0 {
+		result.Removed = s.Removed
+		result.RemovalChecked = true
+	}
+}
+
+func (c *client) GetMySubmissions(ctx context.Context, limit int, after string) ([]Submission, string, error) {
+	if limit < 1 || limit > 100 {
+		return nil, "", fmt.Errorf("limit must be 1 to 100")
+	}
+
+	if c.reddit.appOnly {
+		return nil, "", fmt.Errorf("an application only client has no submissions")
+	}
+
+	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	query := url.Values{
+		"sort":  []string{"new"},
+		"limit": []string{strconv.Itoa(limit)},
+	}
+
+	if after != "" {
+		query.Set("after", after)
+	}
+
+	var l listing
+	err = c.reddit.GetJSON(ctx, fmt.Sprintf("/user/%s/submitted", c.reddit.user()), query, &l)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting submissions of u/%s: %w", c.reddit.user(), err)
+	}
+
+	submissions := make([]Submission, len(l.Data.Children))
+	for i, child := range l.Data.Children {
+		submissions[i] = child.Data.submission()
+	}
+	return submissions, l.Data.After, nil
+}
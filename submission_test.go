@@ -0,0 +1,163 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetSubmission(t *testing.T) {
+	// reddit server. reddit api endpoints
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/info":
+			if got := r.URL.Query().Get("id"); got != "t3_x1qxro" {
+				t.Errorf("want id t3_x1qxro, got %s", got)
+			}
+
+			// reddit escapes & in json unless raw_json is set
+			link := "https://i.redd.it/hsklj75xrxk91.jpg?width=640&format=pjpg"
+			if r.URL.Query().Get("raw_json") != "1" {
+				link = strings.ReplaceAll(link, "&", "&amp;")
+			}
+
+			fmt.Fprintf(w, `{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {
+				"name": "t3_x1qxro",
+				"title": "image test",
+				"subreddit": "subreddit",
+				"url": "%s",
+				"permalink": "/r/subreddit/comments/x1qxro/image_test/",
+				"score": 42,
+				"removed_by_category": "automod_filtered",
+				"created_utc": 1661900000.0
+			}}]}}`, link)
+		default:
+			t.Fatalf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+
+	// When
+	got, err := reddit.GetSubmission(context.Background(), "t3_x1qxro")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	want := Submission{
+		Name:       "t3_x1qxro",
+		Title:      "image test",
+		Subreddit:  "subreddit",
+		URL:        "https://i.redd.it/hsklj75xrxk91.jpg?width=640&format=pjpg",
+		Permalink:  "/r/subreddit/comments/x1qxro/image_test/",
+		Score:      42,
+		Removed:    true,
+		CreatedUTC: time.Unix(1661900000, 0).UTC(),
+	}
+
+	if *got != want {
+		t.Errorf("want %+v, got %+v", want, *got)
+	}
+}
+
+func TestGetMySubmissions(t *testing.T) {
+	// reddit server. the user's submitted listing, two pages of one post
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/user/username/submitted":
+			if got := r.URL.Query().Get("limit"); got != "1" {
+				t.Errorf("want limit 1, got %s", got)
+			}
+
+			switch r.URL.Query().Get("after") {
+			case "":
+				w.Write([]byte(`{"data": {"after": "t3_x1qxro", "children": [{"data": {"name": "t3_x1qxro", "title": "newest"}}]}}`))
+			case "t3_x1qxro":
+				w.Write([]byte(`{"data": {"after": null, "children": [{"data": {"name": "t3_x0abcd", "title": "oldest"}}]}}`))
+			default:
+				t.Errorf("unexpected after %s", r.URL.Query().Get("after"))
+			}
+		default:
+			t.Fatalf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+
+	// When
+	var names []string
+	after := ""
+	for page := 0; page < 3; page++ {
+		submissions, next, err := reddit.GetMySubmissions(context.Background(), 1, after)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range submissions {
+			names = append(names, s.Name)
+		}
+
+		if next == "" {
+			break
+		}
+		after = next
+	}
+
+	// Then
+	if strings.Join(names, ",") != "t3_x1qxro,t3_x0abcd" {
+		t.Errorf("want both pages of submissions, got %v", names)
+	}
+
+	_, _, err := reddit.GetMySubmissions(context.Background(), 101, "")
+	if err == nil {
+		t.Error("want an error for a limit over 100")
+	}
+}
@@ -0,0 +1,70 @@
+package redmed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type subredditAbout struct {
+	Data struct {
+		Over18        bool   `json:"over18"`
+		SubredditType string `json:"subreddit_type"`
+	} `json:"data"`
+}
+
+// SubredditAbout returns what /r/<subreddit>/about reports, caching it per
+// subreddit for the life of the client.
+func (c *reddit) SubredditAbout(ctx context.Context, subreddit string) (subredditAbout, error) {
+	c.aboutMu.Lock()
+	about, ok := c.about[subreddit]
+	c.aboutMu.Unlock()
+	if ok {
+		return about, nil
+	}
+
+	err := c.GetJSON(ctx, fmt.Sprintf("/r/%s/about", url.PathEscape(subreddit)), nil, &about)
+	if err != nil {
+		return subredditAbout{}, err
+	}
+
+	c.aboutMu.Lock()
+	defer c.aboutMu.Unlock()
+	if c.about == nil {
+		c.about = make(map[string]subredditAbout)
+	}
+	c.about[subreddit] = about
+
+	return about, nil
+}
+
+// checkSubreddit runs the subreddit checks enabled on the client before posting
+// to subreddit and returns whether the post is marked nsfw: when requested, or,
+// with WithAutoNSFW, when the subreddit is 18+.
+func (c *client) checkSubreddit(ctx context.Context, subreddit string, nsfw bool) (bool, error) {
+	autoNSFW := c.autoNSFW && !nsfw
+	if !autoNSFW && !c.subredditPrecheck {
+		return nsfw, nil
+	}
+
+	about, err := c.reddit.SubredditAbout(ctx, subreddit)
+	if err != nil {
+		var statusErr *StatusError
+		if c.subredditPrecheck && errors.As(err, &statusErr) && (statusErr.StatusCode() == http.StatusNotFound || statusErr.StatusCode() == http.StatusForbidden) {
+			return false, fmt.Errorf("r/%s does not exist or is private", subreddit)
+		}
+		return false, fmt.Errorf("getting about r/%s: %w", subreddit, err)
+	}
+
+	if c.subredditPrecheck {
+		switch about.Data.SubredditType {
+		case "public", "restricted", "archived", "gold_restricted", "user":
+		default:
+			return false, fmt.Errorf("r/%s does not exist or is private", subreddit)
+		}
+	}
+
+	return nsfw || (autoNSFW && about.Data.Over18), nil
+}
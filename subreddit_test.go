@@ -0,0 +1,155 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithAutoNSFW(t *testing.T) {
+	var aboutRequests int
+
+	// reddit server. reddit api endpoints for an 18+ subreddit
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/r/subreddit/about":
+			aboutRequests++
+			w.Write([]byte(`{"kind": "t5", "data": {"over18": true, "subreddit_type": "public"}}`))
+		case "/api/submit_poll_post.json":
+			var payload struct {
+				NSFW string `json:"nsfw"`
+			}
+			err := json.NewDecoder(r.Body).Decode(&payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if payload.NSFW != "true" {
+				t.Errorf("want nsfw true, got %s", payload.NSFW)
+			}
+
+			w.Write([]byte(`{"json": {"errors": [], "data": {"id": "t3_x1qxro"}}}`))
+		default:
+			t.Fatalf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithAutoNSFW(true),
+	)
+
+	req := PostPollRequest{
+		Duration:  1,
+		Options:   []string{"yes", "no"},
+		Subreddit: "subreddit",
+		Title:     "poll test",
+	}
+
+	// When
+	for i := 0; i < 2; i++ {
+		_, err := reddit.PostPoll(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Then
+	if aboutRequests != 1 {
+		t.Errorf("want about fetched once, got %d", aboutRequests)
+	}
+}
+
+func TestWithSubredditPrecheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		about      string
+	}{
+		{"NotFound", http.StatusNotFound, `{"reason": "banned", "message": "Not Found", "error": 404}`},
+		{"Forbidden", http.StatusForbidden, `{"reason": "private", "message": "Forbidden", "error": 403}`},
+		{"Private", http.StatusOK, `{"kind": "t5", "data": {"subreddit_type": "private"}}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// reddit server. reddit api endpoints for an unavailable subreddit
+			redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/access_token":
+					w.Write([]byte(`{"access_token": "token"}`))
+				case "/r/subreddit/about":
+					w.WriteHeader(tc.statusCode)
+					w.Write([]byte(tc.about))
+				default:
+					t.Fatalf("%s not supported", r.URL.Path)
+				}
+			}))
+			defer redditSvr.Close()
+
+			// save real endpoints
+			originalBaseURL, originalTokenURL := baseURL, tokenURL
+			defer func() {
+				baseURL = originalBaseURL
+				tokenURL = originalTokenURL
+			}()
+
+			// set endpoints to test servers
+			baseURL = redditSvr.URL
+			tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+			// Given
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				},
+			}
+
+			reddit := New("userAgent", "clientID", "secret", "username", "password",
+				WithHTTPClient(httpClient),
+				WithSubredditPrecheck(true),
+			)
+
+			req := PostPollRequest{
+				Duration:  1,
+				Options:   []string{"yes", "no"},
+				Subreddit: "subreddit",
+				Title:     "poll test",
+			}
+
+			// When
+			_, err := reddit.PostPoll(context.Background(), req)
+
+			// Then
+			want := `posting "poll test" to r/subreddit: r/subreddit does not exist or is private`
+			if err == nil || err.Error() != want {
+				t.Errorf("want %s, got %v", want, err)
+			}
+		})
+	}
+}
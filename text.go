@@ -0,0 +1,187 @@
+package redmed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type PostTextWithMediaRequest struct {
+	// Extra holds submit fields redmed doesn't model, sent as given. It can't set
+	// the fields redmed sets itself.
+	Extra   map[string]string
+	FlairID string
+	// FlairName picks the subreddit's flair with this text in place of FlairID.
+	FlairName string
+	FlairText string
+	// Markdown is the body of the post. Each key of Media, in braces, such as
+	// {diagram}, marks where its media goes.
+	Markdown string
+	// Media maps a placeholder name to the local path or link of the image, gif, or
+	// video shown in its place.
+	Media map[string]string
+	// NSWF and SendReplies fall back to the client's defaults when nil.
+	NSWF            *bool
+	OriginalContent bool
+	SendReplies     *bool
+	Spoiler         bool
+	Subreddit       string
+	Title           string
+}
+
+func (c *client) PostTextWithMedia(ctx context.Context, req PostTextWithMediaRequest) (string, error) {
+	req.Subreddit = normalizeSubreddit(req.Subreddit)
+	req.NSWF = boolOr(req.NSWF, c.defaultNSFW)
+	req.SendReplies = boolOr(req.SendReplies, c.defaultSendReplies)
+	result, err := c.postTextWithMedia(ctx, req)
+	if err != nil {
+		return "", postError(req.Subreddit, req.Title, err)
+	}
+	return result, nil
+}
+
+func (c *client) postTextWithMedia(ctx context.Context, req PostTextWithMediaRequest) (string, error) {
+	if req.Markdown == "" {
+		return "", fmt.Errorf("must provide markdown")
+	}
+
+	err := validateTitle(req.Title)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateSubreddit(req.Subreddit)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateExtra(req.Extra)
+	if err != nil {
+		return "", err
+	}
+
+	err = validateFlair(req.FlairID, req.FlairName, req.FlairText)
+	if err != nil {
+		return "", err
+	}
+
+	placeholders := make([]string, 0, len(req.Media))
+	for placeholder := range req.Media {
+		if !strings.Contains(req.Markdown, "{"+placeholder+"}") {
+			return "", fmt.Errorf("markdown has no {%s} for its media", placeholder)
+		}
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+
+	err = c.reddit.SetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	nsfw, err := c.checkSubreddit(ctx, req.Subreddit, boolValue(req.NSWF))
+	if err != nil {
+		return "", err
+	}
+
+	req.FlairID, err = c.checkFlair(ctx, req.Subreddit, req.FlairID, req.FlairName)
+	if err != nil {
+		return "", err
+	}
+
+	markdown := req.Markdown
+	for _, placeholder := range placeholders {
+		path := req.Media[placeholder]
+
+		asset, err := c.reddit.UploadAsset(ctx, path, "")
+		if err != nil {
+			return "", fmt.Errorf("uploading %s (%s): %w", placeholder, path, err)
+		}
+
+		mimeType, _ := mimeTypeOf(path)
+		markdown = strings.ReplaceAll(markdown, "{"+placeholder+"}", inlineMedia(mimeType, asset.ID))
+	}
+
+	richtext, err := c.reddit.ConvertMarkdown(ctx, markdown)
+	if err != nil {
+		return "", fmt.Errorf("converting markdown: %w", err)
+	}
+
+	form := url.Values{}
+	form.Add("kind", "self")
+	form.Add("sr", req.Subreddit)
+	form.Add("title", req.Title)
+	form.Add("richtext_json", string(richtext))
+	form.Add("nsfw", strconv.FormatBool(nsfw))
+	form.Add("sendreplies", strconv.FormatBool(boolValue(req.SendReplies)))
+	form.Add("spoiler", strconv.FormatBool(req.Spoiler))
+
+	if req.FlairID != "" {
+		form.Add("flair_id", req.FlairID)
+	}
+
+	if req.FlairText != "" {
+		form.Add("flair_text", req.FlairText)
+	}
+
+	if req.OriginalContent {
+		form.Add("original_content", "true")
+	}
+
+	for k, v := range req.Extra {
+		form.Set(k, v)
+	}
+
+	name, _, err := c.submit(ctx, "", form)
+	if err != nil {
+		return "", fmt.Errorf("submitting post: %w", err)
+	}
+
+	return name, nil
+}
+
+// inlineMedia returns the markdown reddit converts into the media element for the
+// uploaded asset with id. Media has to be a paragraph of its own.
+func inlineMedia(mimeType, id string) string {
+	element := "img"
+	switch {
+	case mimeType == "image/gif":
+		element = "gif"
+	case strings.HasPrefix(mimeType, "video/"):
+		element = "video"
+	}
+	return fmt.Sprintf("\n\n![%s](%s)\n\n", element, id)
+}
+
+// ConvertMarkdown converts markdown into the richtext json reddit renders posts
+// from, which is the only way to submit inline media.
+func (c *reddit) ConvertMarkdown(ctx context.Context, markdown string) (json.RawMessage, error) {
+	form := url.Values{
+		"output_mode":   []string{"rtjson"},
+		"markdown_text": []string{markdown},
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/convert_rte_body_format", c.apiURL()), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("creating http request: %w", err)
+	}
+	r.Header.Set("Authorization", c.authorization())
+
+	var resp struct {
+		Output json.RawMessage `json:"output"`
+	}
+	_, err = c.doRequest(r, "", json.Unmarshal, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Output) == 0 {
+		return nil, fmt.Errorf("no richtext in response")
+	}
+	return resp.Output, nil
+}
@@ -0,0 +1,168 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPostTextWithMedia(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// action server. where the media is actually uploaded to reddit
+		actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+		}))
+		defer actionSvr.Close()
+
+		actionServerURL, err := url.Parse(actionSvr.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// reddit server. converts the markdown and creates the self post
+		redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := r.ParseForm()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch r.URL.Path {
+			case "/api/v1/access_token":
+				w.Write([]byte(`{"access_token": "token"}`))
+			case "/api/media/asset.json":
+				alr := assetLeaseResponse{}
+				alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+				alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+				alr.Asset.AssedID = "123"
+				err = json.NewEncoder(w).Encode(alr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			case "/api/convert_rte_body_format":
+				if got := r.PostForm.Get("markdown_text"); !strings.Contains(got, "# release\n\n\n![img](123)\n\n\nnotes") {
+					t.Errorf("want the placeholder replaced with the asset, got %q", got)
+				}
+				w.Write([]byte(`{"output": {"document": [{"e": "img", "id": "123"}]}}`))
+			case "/api/submit":
+				if got := r.PostForm.Get("kind"); got != "self" {
+					t.Errorf("want kind self, got %s", got)
+				}
+
+				if got := r.PostForm.Get("richtext_json"); got != `{"document": [{"e": "img", "id": "123"}]}` {
+					t.Errorf("want the converted richtext, got %s", got)
+				}
+				w.Write([]byte(`{"json": {"errors": [], "data": {"name": "t3_x1qxro"}}}`))
+			default:
+				t.Errorf("%s not supported", r.URL.Path)
+			}
+		}))
+		defer redditSvr.Close()
+
+		// save real endpoints
+		originalBaseURL, originalTokenURL := baseURL, tokenURL
+		defer func() {
+			baseURL = originalBaseURL
+			tokenURL = originalTokenURL
+		}()
+
+		// set endpoints to test servers
+		baseURL = redditSvr.URL
+		tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+		// Given
+		httpClient := &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+
+		reddit := New("userAgent", "clientID", "secret", "username", "password", WithHTTPClient(httpClient))
+
+		req := PostTextWithMediaRequest{
+			Markdown:  "# release\n{screenshot}\nnotes",
+			Media:     map[string]string{"screenshot": "testdata/testimg.jpeg"},
+			Subreddit: "subreddit",
+			Title:     "text test",
+		}
+
+		// When
+		name, err := reddit.PostTextWithMedia(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Then
+		if name != "t3_x1qxro" {
+			t.Errorf("want t3_x1qxro, got %s", name)
+		}
+	})
+	t.Run("MissingPlaceholder", func(t *testing.T) {
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		req := PostTextWithMediaRequest{
+			Markdown:  "# release",
+			Media:     map[string]string{"screenshot": "testdata/testimg.jpeg"},
+			Subreddit: "subreddit",
+			Title:     "text test",
+		}
+
+		// When
+		_, err := reddit.PostTextWithMedia(context.Background(), req)
+
+		// Then
+		if err == nil || !strings.Contains(err.Error(), "no {screenshot}") {
+			t.Errorf("want missing placeholder error, got %v", err)
+		}
+	})
+	t.Run("InvalidRequest", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			req     PostTextWithMediaRequest
+			wantErr string
+		}{
+			{
+				"Subreddit",
+				PostTextWithMediaRequest{Subreddit: "r/", Title: "text test"},
+				"is not a valid subreddit name",
+			},
+			{
+				"FlairIDAndName",
+				PostTextWithMediaRequest{Subreddit: "subreddit", Title: "text test", FlairID: "id", FlairName: "name"},
+				"flair id and flair name can't both be set",
+			},
+			{
+				"FlairTextWithoutID",
+				PostTextWithMediaRequest{Subreddit: "subreddit", Title: "text test", FlairText: "text"},
+				"flair text requires a flair id",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				// Given
+				// no test server: failing the checks must stop the post before the
+				// media is uploaded
+				reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+				tc.req.Markdown = "# release\n{screenshot}\nnotes"
+				tc.req.Media = map[string]string{"screenshot": "testdata/testimg.jpeg"}
+
+				// When
+				_, err := reddit.PostTextWithMedia(context.Background(), tc.req)
+
+				// Then
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("want error containing %q, got %v", tc.wantErr, err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// tokenRefreshMargin is how long before it expires a token is replaced, so a
+	// request never goes out with a token that expires in flight.
+	tokenRefreshMargin = time.Minute
+	// tokenRetryInterval is how long the refresher waits after failing to fetch a
+	// token, and the least it waits between fetches.
+	tokenRetryInterval = 10 * time.Second
+)
+
+// TokenInfo describes the oauth token requests to reddit are made with.
+type TokenInfo struct {
+	// Scopes are what the token may be used for, such as submit and read. A
+	// token with every scope has the one scope *.
+	Scopes    []string
+	TokenType string
+	Expiry    time.Time
+}
+
+// HasScope reports whether the token may be used for scope.
+func (ti TokenInfo) HasScope(scope string) bool {
+	for _, s := range ti.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *client) TokenInfo(ctx context.Context) (TokenInfo, error) {
+	err := c.reddit.SetToken(ctx)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	c.reddit.tokenMu.Lock()
+	defer c.reddit.tokenMu.Unlock()
+
+	return TokenInfo{
+		Scopes:    strings.Fields(c.reddit.token.Scope),
+		TokenType: c.reddit.token.TokenType,
+		Expiry:    c.reddit.tokenExpiry,
+	}, nil
+}
+
+func (c *client) StartTokenRefresher(ctx context.Context) error {
+	c.reddit.tokenMu.Lock()
+	running := c.reddit.refresherRunning
+	c.reddit.refresherRunning = true
+	c.reddit.tokenMu.Unlock()
+
+	if running {
+		return fmt.Errorf("token refresher already running")
+	}
+
+	err := c.reddit.fetchToken(ctx)
+	if err != nil {
+		c.reddit.stopRefresher()
+		return fmt.Errorf("setting oauth token: %w", err)
+	}
+
+	go c.reddit.refreshTokens(ctx)
+	return nil
+}
+
+// refreshTokens fetches a new token tokenRefreshMargin before the current one
+// expires until ctx is done. A failed fetch is retried after tokenRetryInterval,
+// and a request in the meantime fetches its own token in SetToken.
+func (c *reddit) refreshTokens(ctx context.Context) {
+	defer c.stopRefresher()
+
+	for {
+		wait := time.Until(c.expiry()) - tokenRefreshMargin
+		if wait < tokenRetryInterval {
+			wait = tokenRetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		c.fetchToken(ctx)
+	}
+}
+
+func (c *reddit) stopRefresher() {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.refresherRunning = false
+}
+
+// tokenValid reports whether the last token fetched is good for at least margin.
+func (c *reddit) tokenValid(margin time.Duration) bool {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.accessToken != "" && time.Until(c.tokenExpiry) > margin
+}
+
+func (c *reddit) expiry() time.Time {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.tokenExpiry
+}
+
+func (c *reddit) scope() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return c.token.Scope
+}
+
+// requiredScope returns the oauth scope the reddit api endpoint at path needs, or
+// "" if it isn't one redmed knows.
+func requiredScope(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/api/submit"), path == "/api/media/asset.json", path == "/api/convert_rte_body_format":
+		return "submit"
+	case strings.HasPrefix(path, "/api/v1/collections/"):
+		return "modposts"
+	case strings.HasSuffix(path, "/api/link_flair_v2"):
+		return "flair"
+	case strings.HasPrefix(path, "/user/"):
+		return "history"
+	case path == "/api/info", strings.HasSuffix(path, "/about"), strings.HasSuffix(path, "/post_requirements"):
+		return "read"
+	}
+
+	switch path {
+	case "/api/marknsfw", "/api/unmarknsfw", "/api/spoiler", "/api/unspoiler", "/api/set_subreddit_sticky", "/api/distinguish",
+		"/api/set_suggested_sort", "/api/set_contest_mode":
+		return "modposts"
+	}
+	return ""
+}
+
+// authorization returns the Authorization header of requests to the reddit api.
+func (c *reddit) authorization() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	return fmt.Sprintf("bearer %s", c.accessToken)
+}
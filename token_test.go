@@ -0,0 +1,336 @@
+package redmed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTokenServer returns a token server that counts the tokens it hands out,
+// each expiring after expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn int, fetches *int32) {
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(fetches, 1)
+		fmt.Fprintf(w, `{"access_token": "token%d", "expires_in": %d}`, n, expiresIn)
+	}))
+	t.Cleanup(tokenSvr.Close)
+
+	// save real endpoint
+	originalTokenURL := tokenURL
+	t.Cleanup(func() {
+		tokenURL = originalTokenURL
+	})
+
+	tokenURL = tokenSvr.URL
+}
+
+func TestSetTokenCached(t *testing.T) {
+	// Given
+	var fetches int32
+	newTokenServer(t, 3600, &fetches)
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+
+	// When
+	for i := 0; i < 3; i++ {
+		err := c.SetToken(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Then
+	if fetches != 1 {
+		t.Errorf("want 1 token fetch, got %d", fetches)
+	}
+}
+
+func TestSetTokenRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantFetches int32
+		wantErr     bool
+	}{
+		{"Unavailable", http.StatusServiceUnavailable, "", 2, false},
+		{"Unauthorized", http.StatusUnauthorized, `{"message": "Unauthorized", "error": 401}`, 1, true},
+		{"InvalidGrant", http.StatusOK, `{"error": "invalid_grant"}`, 1, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// token server. fails the first fetch with the case's response
+			var fetches int32
+			tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&fetches, 1) == 1 {
+					w.WriteHeader(tc.status)
+					w.Write([]byte(tc.body))
+					return
+				}
+				w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+			}))
+			defer tokenSvr.Close()
+
+			// save real endpoint
+			originalTokenURL := tokenURL
+			defer func() {
+				tokenURL = originalTokenURL
+			}()
+			tokenURL = tokenSvr.URL
+
+			// Given
+			c := newReddit("userAgent", "clientID", "secret", "username", "password")
+			c.setTokenRetry(2, time.Millisecond)
+
+			// When
+			err := c.SetToken(context.Background())
+
+			// Then
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+
+			if fetches != tc.wantFetches {
+				t.Errorf("want %d fetches, got %d", tc.wantFetches, fetches)
+			}
+		})
+	}
+}
+
+func TestStartTokenRefresher(t *testing.T) {
+	// save real timings
+	originalMargin, originalInterval := tokenRefreshMargin, tokenRetryInterval
+	t.Cleanup(func() {
+		tokenRefreshMargin, tokenRetryInterval = originalMargin, originalInterval
+	})
+
+	tokenRefreshMargin, tokenRetryInterval = 0, 10*time.Millisecond
+
+	// Given
+	var fetches int32
+	newTokenServer(t, 0, &fetches)
+	reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// When
+	err := reddit.StartTokenRefresher(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	err = reddit.StartTokenRefresher(ctx)
+	if err == nil {
+		t.Error("want an error starting a second refresher")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&fetches) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := atomic.LoadInt32(&fetches); got < 3 {
+		t.Fatalf("want the token refreshed, got %d fetches", got)
+	}
+
+	cancel()
+
+	r := reddit.(*client).reddit
+	for time.Now().Before(deadline) {
+		r.tokenMu.Lock()
+		running := r.refresherRunning
+		r.tokenMu.Unlock()
+		if !running {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("want the refresher stopped once ctx is done")
+}
+
+func TestScopeError(t *testing.T) {
+	// Given
+	redditSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token", "expires_in": 3600, "scope": "identity read"}`))
+		case "/api/marknsfw":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="reddit", error="insufficient_scope"`)
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Forbidden", "error": 403}`))
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	t.Cleanup(redditSvr.Close)
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	t.Cleanup(func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	})
+
+	baseURL = redditSvr.URL
+	tokenURL = redditSvr.URL + "/api/v1/access_token"
+
+	c := newReddit("userAgent", "clientID", "secret", "username", "password")
+	err := c.SetToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	err = c.PostForm(context.Background(), "/api/marknsfw", url.Values{"id": []string{"t3_x1qxro"}})
+
+	// Then
+	var scopeErr *ScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("want a *ScopeError, got %v", err)
+	}
+
+	if scopeErr.Required != "modposts" {
+		t.Errorf("want required scope modposts, got %q", scopeErr.Required)
+	}
+
+	if scopeErr.Granted != "identity read" {
+		t.Errorf("want granted scopes %q, got %q", "identity read", scopeErr.Granted)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode() != http.StatusForbidden {
+		t.Errorf("want a *StatusError with status code 403, got %v", err)
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/submit", "submit"},
+		{"/api/media/asset.json", "submit"},
+		{"/api/spoiler", "modposts"},
+		{"/api/v1/collections/add_post_to_collection", "modposts"},
+		{"/r/subreddit/api/link_flair_v2", "flair"},
+		{"/user/username/submitted", "history"},
+		{"/r/subreddit/about", "read"},
+		{"/api/unknown", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := requiredScope(tc.path); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTokenInfo(t *testing.T) {
+	// Given
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 3600, "scope": "identity submit read"}`))
+	}))
+	t.Cleanup(tokenSvr.Close)
+
+	// save real endpoint
+	originalTokenURL := tokenURL
+	t.Cleanup(func() {
+		tokenURL = originalTokenURL
+	})
+
+	tokenURL = tokenSvr.URL
+
+	reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+	// When
+	info, err := reddit.TokenInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if info.TokenType != "bearer" {
+		t.Errorf("want token type bearer, got %s", info.TokenType)
+	}
+
+	if !info.HasScope("submit") || info.HasScope("modposts") {
+		t.Errorf("want the submit scope and not modposts, got %v", info.Scopes)
+	}
+
+	if until := time.Until(info.Expiry); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("want the token to expire in an hour, got %s", until)
+	}
+}
+
+func TestWithUserPassword(t *testing.T) {
+	// token server. records the password of each fetch
+	var passwords []string
+	tokenSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			t.Fatal(err)
+		}
+		passwords = append(passwords, r.PostForm.Get("password"))
+		w.Write([]byte(`{"access_token": "token", "expires_in": 0}`))
+	}))
+	defer tokenSvr.Close()
+
+	// save real endpoint
+	originalTokenURL := tokenURL
+	defer func() {
+		tokenURL = originalTokenURL
+	}()
+
+	tokenURL = tokenSvr.URL
+
+	// Given
+	password := "first"
+	c := New("userAgent", "clientID", "secret", "username", "password",
+		WithUserPassword(func(ctx context.Context) (string, string, error) {
+			return "rotated", password, nil
+		}),
+	).(*client).reddit
+
+	// When
+	err := c.SetToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password = "second"
+	err = c.SetToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if len(passwords) != 2 || passwords[0] != "first" || passwords[1] != "second" {
+		t.Errorf("want the rotated password on each fetch, got %v", passwords)
+	}
+
+	if got := c.user(); got != "rotated" {
+		t.Errorf("want the provided username, got %s", got)
+	}
+
+	failing := New("userAgent", "clientID", "secret", "username", "password",
+		WithUserPassword(func(ctx context.Context) (string, string, error) {
+			return "", "", errors.New("secret manager unavailable")
+		}),
+	).(*client).reddit
+
+	err = failing.SetToken(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "secret manager unavailable") {
+		t.Errorf("want the provider error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package redmed
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// RequestTiming reports the connection phase timings of a single outgoing request.
+// Phases that did not happen, such as DNS for an IP address or any dialing on a
+// reused connection, are zero.
+type RequestTiming struct {
+	Method          string
+	URL             string
+	ReusedConn      bool
+	DNS             time.Duration
+	Connect         time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+}
+
+type requestTracer struct {
+	mu     sync.Mutex
+	start  time.Time
+	dns    time.Time
+	conn   time.Time
+	tls    time.Time
+	timing RequestTiming
+}
+
+func newRequestTracer(r *http.Request) *requestTracer {
+	return &requestTracer{
+		start: time.Now(),
+		timing: RequestTiming{
+			Method: r.Method,
+			URL:    r.URL.String(),
+		},
+	}
+}
+
+func (t *requestTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNS = time.Since(t.dns)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.conn = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Connect = time.Since(t.conn)
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLSHandshake = time.Since(t.tls)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.ReusedConn = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TimeToFirstByte = time.Since(t.start)
+		},
+	}
+}
+
+func (t *requestTracer) result() RequestTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timing
+}
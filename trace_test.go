@@ -0,0 +1,74 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithHTTPTrace(t *testing.T) {
+	// reddit server. reddit api endpoints
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := token{AccessToken: "token"}
+		b, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoint
+	originalTokenURL := tokenURL
+	defer func() {
+		tokenURL = originalTokenURL
+	}()
+
+	tokenURL = redditSvr.URL + "/api/v1/access_token"
+
+	// Given
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	var timings []RequestTiming
+	c := New("userAgent", "clientID", "secret", "username", "password",
+		WithHTTPClient(httpClient),
+		WithHTTPTrace(func(rt RequestTiming) {
+			timings = append(timings, rt)
+		}),
+	).(*client)
+
+	// When
+	err := c.reddit.SetToken(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if len(timings) != 1 {
+		t.Fatalf("want 1 timing, got %d", len(timings))
+	}
+
+	got := timings[0]
+	if got.Method != http.MethodPost || got.URL != tokenURL {
+		t.Errorf("want POST %s, got %s %s", tokenURL, got.Method, got.URL)
+	}
+
+	if got.Connect <= 0 {
+		t.Errorf("want connect timing, got %s", got.Connect)
+	}
+
+	if got.TLSHandshake <= 0 {
+		t.Errorf("want tls handshake timing, got %s", got.TLSHandshake)
+	}
+
+	if got.TimeToFirstByte <= 0 {
+		t.Errorf("want time to first byte, got %s", got.TimeToFirstByte)
+	}
+}
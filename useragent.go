@@ -0,0 +1,34 @@
+package redmed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genericUserAgents are prefixes of default user agents that reddit throttles or
+// blocks because they don't identify the app.
+var genericUserAgents = []string{
+	"Go-http-client",
+	"curl/",
+	"python-requests",
+	"Mozilla/",
+}
+
+// BuildUserAgent formats a user agent the way reddit's api rules ask for, such as
+// "linux:com.example.bot:v1.0.0 (by /u/username)".
+func BuildUserAgent(platform, appID, version, author string) string {
+	return fmt.Sprintf("%s:%s:%s (by /u/%s)", platform, appID, version, author)
+}
+
+func validateUserAgent(userAgent string) error {
+	if strings.TrimSpace(userAgent) == "" {
+		return fmt.Errorf("must provide a user agent, such as %q", BuildUserAgent("linux", "com.example.bot", "v1.0.0", "username"))
+	}
+
+	for _, prefix := range genericUserAgents {
+		if strings.HasPrefix(userAgent, prefix) {
+			return fmt.Errorf("user agent %q is generic and will be rate limited by reddit; describe the app, such as %q", userAgent, BuildUserAgent("linux", "com.example.bot", "v1.0.0", "username"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package redmed
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestBuildUserAgent(t *testing.T) {
+	got := BuildUserAgent("linux", "com.example.bot", "v1.0.0", "username")
+
+	want := "linux:com.example.bot:v1.0.0 (by /u/username)"
+	if got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestValidateUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		wantErr   bool
+	}{
+		{"Empty", "", true},
+		{"Blank", "  ", true},
+		{"GoDefault", "Go-http-client/1.1", true},
+		{"Browser", "Mozilla/5.0 (X11; Linux x86_64)", true},
+		{"Descriptive", "linux:com.example.bot:v1.0.0 (by /u/username)", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateUserAgent(tc.userAgent)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUserAgentOnEveryRequest(t *testing.T) {
+	const userAgent = "linux:com.example.bot:v1.0.0 (by /u/username)"
+
+	// checkHeaders records a request to server without the user agent, or with a
+	// device id where there should be none
+	var mu sync.Mutex
+	var problems []string
+	checkHeaders := func(server string, r *http.Request, wantDeviceID bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if got := r.Header.Get("User-Agent"); got != userAgent {
+			problems = append(problems, fmt.Sprintf("%s %s: user agent %q", server, r.URL.Path, got))
+		}
+
+		if got := r.Header.Get("X-Reddit-Device-Id"); (got == "device") != wantDeviceID {
+			problems = append(problems, fmt.Sprintf("%s %s: device id %q", server, r.URL.Path, got))
+		}
+	}
+
+	// websocket server. reports the post
+	wsSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkHeaders("websocket", r, true)
+
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		c.WriteMessage(websocket.TextMessage, []byte(`{"type": "success", "payload": {"redirect": "https://www.reddit.com/r/subreddit/comments/x1qxro/title/"}}`))
+	}))
+	defer wsSvr.Close()
+
+	// link server. where the image is downloaded from
+	linkSvr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkHeaders("link", r, false)
+
+		b, err := os.ReadFile("testdata/testimg.jpeg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+	defer linkSvr.Close()
+
+	// action server. where the media is actually uploaded to reddit
+	actionSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkHeaders("action", r, false)
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<PostResponse><Location>https://reddit-uploaded-media.s3-accelerate.amazonaws.com/rte_images%2Fhsklj75xrxk91</Location></PostResponse>"))
+	}))
+	defer actionSvr.Close()
+
+	actionServerURL, err := url.Parse(actionSvr.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reddit server. reddit api endpoints
+	redditSvr := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkHeaders("reddit", r, true)
+
+		switch r.URL.Path {
+		case "/api/v1/access_token":
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/api/media/asset.json":
+			alr := assetLeaseResponse{}
+			alr.Args.Fields = []leaseField{{Name: "key", Value: "value"}}
+			alr.Args.Action = fmt.Sprintf("//127.0.0.1:%s", actionServerURL.Port())
+			alr.Asset.AssedID = "123"
+			alr.Asset.WebsocketURL = "ws" + strings.TrimPrefix(wsSvr.URL, "http")
+			err := json.NewEncoder(w).Encode(alr)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case "/api/submit":
+		default:
+			t.Errorf("%s not supported", r.URL.Path)
+		}
+	}))
+	defer redditSvr.Close()
+
+	// save real endpoints
+	originalBaseURL, originalTokenURL := baseURL, tokenURL
+	defer func() {
+		baseURL = originalBaseURL
+		tokenURL = originalTokenURL
+	}()
+
+	// set endpoints to test servers
+	baseURL = redditSvr.URL
+	tokenURL = fmt.Sprintf("%s/%s", redditSvr.URL, "api/v1/access_token")
+
+	// Given
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	reddit := New(userAgent, "clientID", "secret", "username", "password",
+		WithHTTPClient(client),
+		WithDeviceID("device"),
+	)
+
+	req := PostImageRequest{
+		Path:      linkSvr.URL + "/image.jpeg",
+		Subreddit: "subreddit",
+		Title:     "image test",
+	}
+
+	// When
+	_, err = reddit.PostImage(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	for _, p := range problems {
+		t.Error(p)
+	}
+}
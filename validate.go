@@ -0,0 +1,467 @@
+package redmed
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// upload limits reddit documents for each kind of media
+const (
+	maxImageSize int64 = 20 << 20
+	maxGIFSize   int64 = 100 << 20
+	maxVideoSize int64 = 1 << 30
+)
+
+// MaxTitleRunes is the most characters reddit allows in a post title
+const MaxTitleRunes = 300
+
+// MaxCaptionRunes is the most characters reddit allows in a gallery item caption
+const MaxCaptionRunes = 180
+
+// subredditPattern matches the names reddit allows for a subreddit, or for a
+// user's profile as u_username.
+var subredditPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_]{1,20}|u_[A-Za-z0-9_-]{3,20})$`)
+
+// managedFields are the submit fields redmed sets itself, which a request's Extra
+// can't override.
+var managedFields = map[string]bool{
+	"api_type": true, "duration": true, "flair_id": true, "flair_text": true, "items": true,
+	"kind": true, "nsfw": true, "options": true, "original_content": true, "resubmit": true,
+	"richtext_json": true, "sendreplies": true, "show_error_list": true, "spoiler": true,
+	"sr": true, "text": true, "title": true, "url": true, "validate_on_submit": true,
+	"video_poster_height": true, "video_poster_url": true, "video_poster_width": true,
+}
+
+// ValidationError lists every problem found while validating media.
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+func (c *client) ValidateMedia(ctx context.Context, path string, kind string) error {
+	localPath := path
+	fsys := c.reddit.files()
+	if isValidURL(path) {
+		var err error
+		localPath, err = c.reddit.downloadLink(ctx, path, nil)
+		if err != nil {
+			return fmt.Errorf("downloading %s: %w", path, err)
+		}
+		defer os.Remove(localPath)
+		fsys = osFS{}
+	}
+
+	problems, err := validateMedia(fsys, path, localPath, kind)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Path: path, Problems: problems}
+	}
+	return nil
+}
+
+// validateMedia checks the file at localPath in fsys, named by path, against the
+// constraints reddit has for kind. It returns the problems found, or an error if
+// the file could not be read at all.
+func validateMedia(fsys fs.FS, path, localPath, kind string) ([]string, error) {
+	var wantPrefixes []string
+	switch kind {
+	case "image":
+		wantPrefixes = []string{"image/"}
+	case "video":
+		wantPrefixes = []string{"video/"}
+	case "videogif":
+		// a gif is posted as a videogif, as PostVideo does with one
+		wantPrefixes = []string{"video/", "image/gif"}
+	default:
+		return nil, fmt.Errorf("kind must be image, video, or videogif")
+	}
+
+	info, err := fs.Stat(fsys, localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+
+	mimeType, err := mimeTypeOf(path)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else if err := validateMediaPath(path, mimeType, kind, wantPrefixes...); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	maxSize := maxVideoSize
+	switch {
+	case mimeType == "image/gif":
+		maxSize = maxGIFSize
+	case strings.HasPrefix(mimeType, "image/"), kind == "image":
+		maxSize = maxImageSize
+	}
+
+	if info.Size() == 0 {
+		problems = append(problems, "file is empty")
+	} else if info.Size() > maxSize {
+		problems = append(problems, fmt.Sprintf("size of %d bytes exceeds the maximum of %d bytes", info.Size(), maxSize))
+	}
+
+	if strings.HasPrefix(mimeType, "image/") && info.Size() > 0 {
+		problem, err := validateImage(fsys, localPath, mimeType)
+		if err != nil {
+			return nil, err
+		}
+
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems, nil
+}
+
+// validateImage decodes the image header at path in fsys to check it is really of
+// mimeType and has usable dimensions.
+func validateImage(fsys fs.FS, path string, mimeType string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	config, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return "image could not be decoded", nil
+	}
+
+	if got := "image/" + format; got != mimeType {
+		return fmt.Sprintf("content is %s, not %s", got, mimeType), nil
+	}
+
+	if config.Width == 0 || config.Height == 0 {
+		return fmt.Sprintf("invalid dimensions %dx%d", config.Width, config.Height), nil
+	}
+
+	return "", nil
+}
+
+func validateTitle(title string) error {
+	if title == "" {
+		return fmt.Errorf("must provide a title")
+	}
+
+	if utf8.RuneCountInString(title) > MaxTitleRunes {
+		return fmt.Errorf("title exceeds %d characters", MaxTitleRunes)
+	}
+	return nil
+}
+
+func validateSubreddit(subreddit string) error {
+	if subreddit == "" {
+		return fmt.Errorf("must provide a subreddit")
+	}
+
+	if !subredditPattern.MatchString(normalizeSubreddit(subreddit)) {
+		return fmt.Errorf("%s is not a valid subreddit name", subreddit)
+	}
+	return nil
+}
+
+// normalizeSubreddit returns the name of subreddit written the ways people tend
+// to, such as r/subreddit or /r/subreddit/, which reddit won't submit to.
+func normalizeSubreddit(subreddit string) string {
+	name := strings.Trim(subreddit, "/")
+	if len(name) > 2 && strings.EqualFold(name[:2], "r/") {
+		name = strings.Trim(name[2:], "/")
+	}
+	return name
+}
+
+func validateExtra(extra map[string]string) error {
+	for k := range extra {
+		if managedFields[k] {
+			return fmt.Errorf("extra field %s is set by redmed", k)
+		}
+	}
+	return nil
+}
+
+func validateFilename(fileName string) error {
+	if strings.ContainsAny(fileName, `/\`) {
+		return fmt.Errorf("filename %s can't be a path", fileName)
+	}
+	return nil
+}
+
+// validateFlair checks the flair fields of a request. reddit only applies flair
+// text as the text of a flair template.
+func validateFlair(flairID, flairName, flairText string) error {
+	if flairID != "" && flairName != "" {
+		return fmt.Errorf("flair id and flair name can't both be set")
+	}
+
+	if flairText != "" && flairID == "" && flairName == "" {
+		return fmt.Errorf("flair text requires a flair id")
+	}
+	return nil
+}
+
+// validateMediaPath checks that the media at path is of a type a post of kind
+// takes, one of wantPrefixes. mimeType overrides the type looked up from path.
+func validateMediaPath(path, mimeType, kind string, wantPrefixes ...string) error {
+	if mimeType == "" {
+		var err error
+		mimeType, err = mimeTypeOf(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, prefix := range wantPrefixes {
+		if strings.HasPrefix(mimeType, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not supported for %s posts", mimeType, kind)
+}
+
+// ValidateImageRequest checks req for the problems that can be found without
+// calling reddit: the title, subreddit, media type, and flair. PostImage runs the
+// same checks.
+func ValidateImageRequest(req PostImageRequest) error {
+	if req.Path == "" {
+		return fmt.Errorf("must proivde a local path or link to image")
+	}
+
+	err := validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubreddit(req.Subreddit)
+	if err != nil {
+		return err
+	}
+
+	err = validateMimeType(req.MimeType)
+	if err != nil {
+		return err
+	}
+
+	err = validateMediaPath(req.Path, req.MimeType, "image", "image/")
+	if err != nil {
+		return err
+	}
+
+	if req.AsGallery {
+		if req.MimeType != "" {
+			return fmt.Errorf("mime type can't be overridden for a gallery")
+		}
+
+		err = validateGalleryItem(req.Caption, req.OutboundURL)
+		if err != nil {
+			return err
+		}
+	} else if req.Caption != "" || req.OutboundURL != "" {
+		return fmt.Errorf("caption and outbound url need AsGallery")
+	}
+
+	err = validateFilename(req.Filename)
+	if err != nil {
+		return err
+	}
+
+	err = validateExtra(req.Extra)
+	if err != nil {
+		return err
+	}
+
+	return validateFlair(req.FlairID, req.FlairName, req.FlairText)
+}
+
+// ValidateVideoRequest checks req for the problems that can be found without
+// calling reddit: the title, subreddit, kind, media types, and flair. PostVideo
+// runs the same checks.
+func ValidateVideoRequest(req PostVideoRequest) error {
+	if req.VideoPath == "" {
+		return fmt.Errorf("must provide a local path or link to video")
+	}
+
+	err := validateMimeType(req.MimeType)
+	if err != nil {
+		return err
+	}
+
+	videoType := req.MimeType
+	if videoType == "" {
+		videoType, _ = mimeTypeOf(req.VideoPath)
+	}
+
+	// a gif's first frame is its thumbnail unless one is given
+	gifThumbnail := req.ThumbnailPath == "" && videoType == "image/gif"
+
+	if req.ThumbnailPath == "" && !gifThumbnail {
+		return fmt.Errorf("must provide a local path or link to thumbnail image")
+	}
+
+	err = validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubreddit(req.Subreddit)
+	if err != nil {
+		return err
+	}
+
+	if req.Kind != "" && req.Kind != VideoKindVideo && req.Kind != VideoKindGif {
+		return fmt.Errorf("kind must be video or videogif")
+	}
+
+	if req.PosterWidth < 0 || req.PosterHeight < 0 || (req.PosterWidth == 0) != (req.PosterHeight == 0) {
+		return fmt.Errorf("poster width and height must both be positive or both be 0")
+	}
+
+	err = validateMediaPath(req.VideoPath, req.MimeType, "video", "video/", "image/gif")
+	if err != nil {
+		return err
+	}
+
+	if !gifThumbnail {
+		thumbnailType, err := mimeTypeOf(req.ThumbnailPath)
+		if err != nil {
+			return fmt.Errorf("thumbnail: %w", err)
+		}
+
+		if !strings.HasPrefix(thumbnailType, "image/") {
+			return fmt.Errorf("thumbnail must be an image")
+		}
+	}
+
+	err = validateFilename(req.Filename)
+	if err != nil {
+		return err
+	}
+
+	err = validateExtra(req.Extra)
+	if err != nil {
+		return err
+	}
+
+	return validateFlair(req.FlairID, req.FlairName, req.FlairText)
+}
+
+// ValidateGalleryRequest checks req for the problems that can be found without
+// calling reddit: the title, subreddit, media types, and flair. PostGallery runs
+// the same checks.
+func ValidateGalleryRequest(req PostGalleryRequest) error {
+	if len(req.Paths) == 0 {
+		return fmt.Errorf("must provide local paths or links to images")
+	}
+
+	if len(req.DownloadHeaders) > len(req.Paths) {
+		return fmt.Errorf("got download headers for %d items, but only %d paths", len(req.DownloadHeaders), len(req.Paths))
+	}
+
+	if len(req.Captions) > len(req.Paths) || len(req.OutboundURLs) > len(req.Paths) {
+		return fmt.Errorf("got captions or outbound urls for more items than the %d paths", len(req.Paths))
+	}
+
+	err := validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubreddit(req.Subreddit)
+	if err != nil {
+		return err
+	}
+
+	for i, path := range req.Paths {
+		err = validateMediaPath(path, "", "gallery", "image/")
+		if err == nil {
+			err = validateGalleryItem(galleryValue(req.Captions, i), galleryValue(req.OutboundURLs, i))
+		}
+
+		if err != nil {
+			return fmt.Errorf("item %d (%s): %w", i, path, err)
+		}
+	}
+
+	err = validateExtra(req.Extra)
+	if err != nil {
+		return err
+	}
+
+	return validateFlair(req.FlairID, req.FlairName, req.FlairText)
+}
+
+func validateGalleryItem(caption, outboundURL string) error {
+	if utf8.RuneCountInString(caption) > MaxCaptionRunes {
+		return fmt.Errorf("caption exceeds %d characters", MaxCaptionRunes)
+	}
+
+	if outboundURL != "" && !isValidURL(outboundURL) {
+		return fmt.Errorf("outbound url %s is not a valid url", outboundURL)
+	}
+	return nil
+}
+
+// ValidatePollRequest checks req for the problems that can be found without
+// calling reddit: the options and their image types, duration, title, subreddit,
+// and flair. PostPoll runs the same checks.
+func ValidatePollRequest(req PostPollRequest) error {
+	if len(req.Options) < 2 || len(req.Options) > 6 {
+		return fmt.Errorf("must provide 2 to 6 options")
+	}
+
+	if len(req.OptionImages) > len(req.Options) {
+		return fmt.Errorf("got images for %d options, but only %d options", len(req.OptionImages), len(req.Options))
+	}
+
+	for i, path := range req.OptionImages {
+		if path == "" {
+			continue
+		}
+
+		err := validateMediaPath(path, "", "poll", "image/")
+		if err != nil {
+			return fmt.Errorf("option %d: %w", i, err)
+		}
+	}
+
+	if req.Duration < 1 || req.Duration > 7 {
+		return fmt.Errorf("duration must be 1 to 7 days")
+	}
+
+	err := validateTitle(req.Title)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubreddit(req.Subreddit)
+	if err != nil {
+		return err
+	}
+
+	err = validateExtra(req.Extra)
+	if err != nil {
+		return err
+	}
+
+	return validateFlair(req.FlairID, req.FlairName, req.FlairText)
+}
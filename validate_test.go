@@ -0,0 +1,288 @@
+package redmed
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateMedia(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		err := reddit.ValidateMedia(context.Background(), "testdata/testimg.jpeg", "image")
+
+		// Then
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("AggregatesProblems", func(t *testing.T) {
+		// a video far larger than any image reddit accepts
+		path := filepath.Join(t.TempDir(), "big.mp4")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = f.Truncate(maxImageSize + 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		err = reddit.ValidateMedia(context.Background(), path, "image")
+
+		// Then
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) {
+			t.Fatalf("want *ValidationError, got %v", err)
+		}
+
+		if len(vErr.Problems) != 2 {
+			t.Errorf("want 2 problems, got %v", vErr.Problems)
+		}
+	})
+	t.Run("VideoGIF", func(t *testing.T) {
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+		path := writeTestGIF(t, t.TempDir())
+
+		// When
+		err := reddit.ValidateMedia(context.Background(), path, "videogif")
+
+		// Then
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("VideoGIFTooLarge", func(t *testing.T) {
+		// a gif over the gif limit, which a videogif post can't take either
+		path := filepath.Join(t.TempDir(), "big.gif")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = f.Truncate(maxGIFSize + 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		// Given
+		reddit := New("userAgent", "clientID", "secret", "username", "password")
+
+		// When
+		err = reddit.ValidateMedia(context.Background(), path, "videogif")
+
+		// Then
+		var vErr *ValidationError
+		if !errors.As(err, &vErr) {
+			t.Fatalf("want *ValidationError, got %v", err)
+		}
+
+		if len(vErr.Problems) == 0 || !strings.Contains(vErr.Problems[0], "exceeds the maximum") {
+			t.Errorf("want the size problem, got %v", vErr.Problems)
+		}
+	})
+}
+
+func TestValidateTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		wantErr bool
+	}{
+		{"Empty", "", true},
+		{"MaxMultibyte", strings.Repeat("é", 300), false},
+		{"TooLong", strings.Repeat("a", 301), true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateTitle(tc.title)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeSubreddit(t *testing.T) {
+	tests := []struct {
+		subreddit string
+		want      string
+	}{
+		{"subreddit", "subreddit"},
+		{"r/subreddit", "subreddit"},
+		{"/r/subreddit/", "subreddit"},
+		{"R/subreddit", "subreddit"},
+		{"subreddit/", "subreddit"},
+		{"u_username", "u_username"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.subreddit, func(t *testing.T) {
+			if got := normalizeSubreddit(tc.subreddit); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr string
+	}{
+		{
+			name: "ImageValid",
+			err:  ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title"}),
+		},
+		{
+			name:    "ImageNoSubreddit",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Title: "title"}),
+			wantErr: "must provide a subreddit",
+		},
+		{
+			name: "ImageSubredditPrefixed",
+			err:  ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "/r/subreddit", Title: "title"}),
+		},
+		{
+			name: "ImageTwoLetterSubreddit",
+			err:  ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "r/de", Title: "title"}),
+		},
+		{
+			name:    "ImageOneLetterSubreddit",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "d", Title: "title"}),
+			wantErr: "d is not a valid subreddit name",
+		},
+		{
+			name:    "ImageInvalidSubreddit",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "r/sub reddit", Title: "title"}),
+			wantErr: "r/sub reddit is not a valid subreddit name",
+		},
+		{
+			name:    "ImageNotAnImage",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/video.mp4", Subreddit: "subreddit", Title: "title"}),
+			wantErr: "video/mp4 is not supported for image posts",
+		},
+		{
+			name:    "ImageFlairTextWithoutID",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", FlairText: "flair"}),
+			wantErr: "flair text requires a flair id",
+		},
+		{
+			name:    "ImageFlairIDAndName",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", FlairID: "id", FlairName: "OC"}),
+			wantErr: "flair id and flair name can't both be set",
+		},
+		{
+			name: "ImageFlairTextWithName",
+			err:  ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", FlairName: "OC", FlairText: "flair"}),
+		},
+		{
+			name:    "ImageTitleTooLong",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: strings.Repeat("a", MaxTitleRunes+1)}),
+			wantErr: "title exceeds 300 characters",
+		},
+		{
+			name:    "ImageCaptionWithoutGallery",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", Caption: "caption"}),
+			wantErr: "caption and outbound url need AsGallery",
+		},
+		{
+			name:    "ImageGalleryCaptionTooLong",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", AsGallery: true, Caption: strings.Repeat("a", MaxCaptionRunes+1)}),
+			wantErr: "caption exceeds 180 characters",
+		},
+		{
+			name:    "GalleryInvalidOutboundURL",
+			err:     ValidateGalleryRequest(PostGalleryRequest{Paths: []string{"/path/to/image.jpeg"}, Subreddit: "subreddit", Title: "title", OutboundURLs: []string{"example.com"}}),
+			wantErr: "item 0 (/path/to/image.jpeg): outbound url example.com is not a valid url",
+		},
+		{
+			name: "VideoGIFWithoutThumbnail",
+			err:  ValidateVideoRequest(PostVideoRequest{VideoPath: "/path/to/video.gif", Subreddit: "subreddit", Title: "title"}),
+		},
+		{
+			name:    "VideoNotAVideo",
+			err:     ValidateVideoRequest(PostVideoRequest{VideoPath: "/path/to/image.jpeg", ThumbnailPath: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title"}),
+			wantErr: "image/jpeg is not supported for video posts",
+		},
+		{
+			name:    "VideoUnknownKind",
+			err:     ValidateVideoRequest(PostVideoRequest{VideoPath: "/path/to/video.mp4", ThumbnailPath: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", Kind: "gif"}),
+			wantErr: "kind must be video or videogif",
+		},
+		{
+			name:    "VideoPosterWidthOnly",
+			err:     ValidateVideoRequest(PostVideoRequest{VideoPath: "/path/to/video.mp4", ThumbnailPath: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", PosterWidth: 1280}),
+			wantErr: "poster width and height must both be positive or both be 0",
+		},
+		{
+			name:    "GalleryItemNotAnImage",
+			err:     ValidateGalleryRequest(PostGalleryRequest{Paths: []string{"/path/to/image.jpeg", "/path/to/video.mp4"}, Subreddit: "subreddit", Title: "title"}),
+			wantErr: "item 1 (/path/to/video.mp4): video/mp4 is not supported for gallery posts",
+		},
+		{
+			name:    "ImageFilenamePath",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", Filename: "dir/cat.jpeg"}),
+			wantErr: "filename dir/cat.jpeg can't be a path",
+		},
+		{
+			name:    "ImageExtraManaged",
+			err:     ValidateImageRequest(PostImageRequest{Path: "/path/to/image.jpeg", Subreddit: "subreddit", Title: "title", Extra: map[string]string{"sr": "other"}}),
+			wantErr: "extra field sr is set by redmed",
+		},
+		{
+			name: "GalleryExtra",
+			err:  ValidateGalleryRequest(PostGalleryRequest{Paths: []string{"/path/to/image.jpeg"}, Subreddit: "subreddit", Title: "title", Extra: map[string]string{"collection_id": "id"}}),
+		},
+		{
+			name:    "PollTooFewOptions",
+			err:     ValidatePollRequest(PostPollRequest{Options: []string{"yes"}, Duration: 1, Subreddit: "subreddit", Title: "title"}),
+			wantErr: "must provide 2 to 6 options",
+		},
+		{
+			name: "PollOptionImage",
+			err:  ValidatePollRequest(PostPollRequest{OptionImages: []string{"", "/path/to/image.jpeg"}, Options: []string{"yes", "no"}, Duration: 1, Subreddit: "subreddit", Title: "title"}),
+		},
+		{
+			name:    "PollTooManyOptionImages",
+			err:     ValidatePollRequest(PostPollRequest{OptionImages: []string{"/path/to/a.jpeg", "/path/to/b.jpeg", "/path/to/c.jpeg"}, Options: []string{"yes", "no"}, Duration: 1, Subreddit: "subreddit", Title: "title"}),
+			wantErr: "got images for 3 options, but only 2 options",
+		},
+		{
+			name:    "PollOptionImageNotAnImage",
+			err:     ValidatePollRequest(PostPollRequest{OptionImages: []string{"/path/to/video.mp4"}, Options: []string{"yes", "no"}, Duration: 1, Subreddit: "subreddit", Title: "title"}),
+			wantErr: "option 0: video/mp4 is not supported for poll posts",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.wantErr == "" {
+				if tc.err != nil {
+					t.Errorf("want no error, got %v", tc.err)
+				}
+				return
+			}
+
+			if tc.err == nil || tc.err.Error() != tc.wantErr {
+				t.Errorf("want %s, got %v", tc.wantErr, tc.err)
+			}
+		})
+	}
+}